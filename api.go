@@ -0,0 +1,429 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"github.com/MorpheusXAUT/eveapi"
+	"github.com/Sirupsen/logrus"
+	"github.com/garyburd/redigo/redis"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	APIPrefix            = "/api/v1"
+	DefaultAPIAddress    = "127.0.0.1:8080"
+	APIShutdownTimeout   = 5
+	APITimerReinforced   = "reinforced"
+	APITimerOnlining     = "onlining"
+	APIRequestTimeout    = 60
+	APIAuthorizationType = "Bearer"
+)
+
+type APIStarbase struct {
+	ID              int                 `json:"id"`
+	LocationName    string              `json:"locationName"`
+	SolarSystemID   int                 `json:"solarSystemID"`
+	SolarSystemName string              `json:"solarSystemName"`
+	RegionID        int                 `json:"regionID"`
+	RegionName      string              `json:"regionName"`
+	OwnerID         int                 `json:"ownerID"`
+	OwnerName       string              `json:"ownerName"`
+	State           string              `json:"state"`
+	StateTimestamp  time.Time           `json:"stateTimestamp"`
+	OnlineTimestamp time.Time           `json:"onlineTimestamp"`
+	Size            string              `json:"size"`
+	Monitored       bool                `json:"monitored"`
+	HoursRemaining  float64             `json:"hoursRemaining"`
+	CachedUntil     time.Time           `json:"cachedUntil"`
+	Fuel            []*APIFuel          `json:"fuel"`
+	Threshold       *FuelThreshold      `json:"threshold,omitempty"`
+	Acknowledgement *APIAcknowledgement `json:"acknowledgement,omitempty"`
+	Ignore          *APIStarbaseIgnore  `json:"ignore,omitempty"`
+}
+
+type APIFuel struct {
+	TypeID             int     `json:"typeID"`
+	TypeName           string  `json:"typeName"`
+	Quantity           int     `json:"quantity"`
+	RequiredPerHour    int     `json:"requiredPerHour"`
+	ConstantlyRequired bool    `json:"constantlyRequired"`
+	HoursRemaining     float64 `json:"hoursRemaining"`
+}
+
+type APIAcknowledgement struct {
+	UserName       string    `json:"userName"`
+	Stage          int       `json:"stage"`
+	AcknowledgedAt time.Time `json:"acknowledgedAt"`
+	Expires        time.Time `json:"expires"`
+}
+
+type APIStarbaseIgnore struct {
+	Config    bool      `json:"config"`
+	UserName  string    `json:"userName,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	IgnoredAt time.Time `json:"ignoredAt,omitempty"`
+	Expires   time.Time `json:"expires,omitempty"`
+}
+
+type APIAlert struct {
+	StarbaseID      int                 `json:"starbaseID"`
+	LocationName    string              `json:"locationName"`
+	OwnerName       string              `json:"ownerName"`
+	FuelTypeID      int                 `json:"fuelTypeID"`
+	FuelTypeName    string              `json:"fuelTypeName"`
+	HoursRemaining  float64             `json:"hoursRemaining"`
+	Stage           string              `json:"stage"`
+	Severity        string              `json:"severity"`
+	Acknowledgement *APIAcknowledgement `json:"acknowledgement,omitempty"`
+}
+
+type APITimer struct {
+	StarbaseID   int       `json:"starbaseID"`
+	LocationName string    `json:"locationName"`
+	OwnerName    string    `json:"ownerName"`
+	Type         string    `json:"type"`
+	Time         time.Time `json:"time"`
+}
+
+type apiError struct {
+	Error string `json:"error"`
+}
+
+func newAPIStarbase(pos *POS) *APIStarbase {
+	starbase := &APIStarbase{
+		ID:              pos.ID,
+		LocationName:    pos.LocationName,
+		SolarSystemID:   pos.SolarSystemID,
+		SolarSystemName: pos.SolarSystemName,
+		RegionID:        pos.RegionID,
+		RegionName:      pos.RegionName,
+		OwnerID:         pos.OwnerID,
+		OwnerName:       pos.OwnerName,
+		State:           pos.State.String(),
+		StateTimestamp:  pos.StateTimestamp,
+		OnlineTimestamp: pos.OnlineTimestamp,
+		Size:            pos.Size.String(),
+		Monitored:       pos.Monitored,
+		HoursRemaining:  pos.HoursRemaining(),
+		CachedUntil:     pos.CachedUntil,
+		Fuel:            make([]*APIFuel, 0),
+	}
+	for _, fuel := range pos.Fuel {
+		starbase.Fuel = append(starbase.Fuel, &APIFuel{
+			TypeID:             fuel.TypeID,
+			TypeName:           fuel.TypeName,
+			Quantity:           fuel.Quantity,
+			RequiredPerHour:    fuel.Required,
+			ConstantlyRequired: fuel.ConstantlyRequired,
+			HoursRemaining:     fuel.HoursRemaining,
+		})
+	}
+
+	return starbase
+}
+
+func newAPIAcknowledgement(ack *StarbaseAcknowledgement) *APIAcknowledgement {
+	if ack == nil {
+		return nil
+	}
+
+	return &APIAcknowledgement{
+		UserName:       ack.UserName,
+		Stage:          ack.Stage,
+		AcknowledgedAt: ack.AcknowledgedAt,
+		Expires:        ack.Expires,
+	}
+}
+
+func (b *Bot) startAPIServer() {
+	b.api = &http.Server{
+		Addr:         b.config.API.Address,
+		Handler:      b.newAPIHandler(),
+		ReadTimeout:  time.Second * APIRequestTimeout,
+		WriteTimeout: time.Second * APIRequestTimeout,
+	}
+
+	go func() {
+		log.WithField("address", b.config.API.Address).Info("Starting HTTP API server")
+		if err := b.api.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.WithField("address", b.config.API.Address).WithError(err).Error("HTTP API server stopped unexpectedly")
+		}
+	}()
+}
+
+func (b *Bot) stopAPIServer() {
+	if b.api == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*APIShutdownTimeout)
+	defer cancel()
+
+	if err := b.api.Shutdown(ctx); err != nil {
+		log.WithError(err).Warn("Failed to shut down HTTP API server cleanly")
+	}
+}
+
+func (b *Bot) newAPIHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(HealthPath, b.handleHealth)
+	mux.HandleFunc(ReadinessPath, b.handleReadiness)
+	mux.Handle(APIPrefix+"/starbases", b.authenticateAPI(b.handleAPIStarbases))
+	mux.Handle(APIPrefix+"/starbases/", b.authenticateAPI(b.handleAPIStarbase))
+	mux.Handle(APIPrefix+"/alerts", b.authenticateAPI(b.handleAPIAlerts))
+	mux.Handle(APIPrefix+"/timers", b.authenticateAPI(b.handleAPITimers))
+
+	if b.config.API.Metrics.Enabled {
+		mux.Handle(MetricsPath, b.authenticateAPI(newMetricsHandler()))
+	}
+
+	if b.config.API.Dashboard.Enabled {
+		mux.Handle(DashboardPath, b.authenticateDashboard(b.handleDashboard))
+	}
+
+	return mux
+}
+
+func (b *Bot) authenticateAPI(handler http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeAPIError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		if !b.isValidAPIToken(apiTokenFromRequest(r)) {
+			log.WithFields(logrus.Fields{
+				"path":   r.URL.Path,
+				"remote": r.RemoteAddr,
+			}).Warn("Rejected unauthenticated API request")
+			w.Header().Set("WWW-Authenticate", APIAuthorizationType)
+			writeAPIError(w, http.StatusUnauthorized, "Missing or invalid API token")
+			return
+		}
+
+		handler(w, r)
+	})
+}
+
+func apiTokenFromRequest(r *http.Request) string {
+	authorization := r.Header.Get("Authorization")
+	if len(authorization) > len(APIAuthorizationType)+1 && strings.EqualFold(authorization[:len(APIAuthorizationType)], APIAuthorizationType) {
+		return strings.TrimSpace(authorization[len(APIAuthorizationType)+1:])
+	}
+
+	return ""
+}
+
+func (b *Bot) isValidAPIToken(token string) bool {
+	return isValidToken(token, b.config.API.Tokens)
+}
+
+func (b *Bot) isValidDashboardToken(token string) bool {
+	return isValidToken(token, b.config.API.Dashboard.Tokens)
+}
+
+func isValidToken(token string, tokens []string) bool {
+	if len(token) == 0 {
+		return false
+	}
+
+	valid := false
+	for _, configured := range tokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(configured)) == 1 {
+			valid = true
+		}
+	}
+
+	return valid
+}
+
+func writeAPIJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.WithError(err).Warn("Failed to write API response")
+	}
+}
+
+func writeAPIError(w http.ResponseWriter, status int, message string) {
+	writeAPIJSON(w, status, &apiError{Error: message})
+}
+
+func (b *Bot) handleAPIStarbases(w http.ResponseWriter, r *http.Request) {
+	args := make(map[string]interface{})
+	for _, arg := range posFilterArguments() {
+		value := r.URL.Query().Get(arg.Name)
+		if len(value) == 0 {
+			continue
+		}
+
+		parsed, err := arg.parse(value)
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, (&CommandArgumentError{Argument: arg, Value: value}).Error())
+			return
+		}
+		args[arg.Name] = parsed
+	}
+
+	poses, total, err := b.getFilteredPOSes(parsePOSFilter(&CommandContext{Arguments: args}))
+	if err != nil {
+		log.WithError(err).Warn("Failed to retrieve POSes for API request")
+		writeAPIError(w, http.StatusBadGateway, "Failed to retrieve POSes")
+		return
+	}
+
+	starbases := make([]*APIStarbase, 0)
+	for _, pos := range poses {
+		starbases = append(starbases, newAPIStarbase(pos))
+	}
+
+	writeAPIJSON(w, http.StatusOK, map[string]interface{}{
+		"total":     total,
+		"count":     len(starbases),
+		"starbases": starbases,
+	})
+}
+
+func (b *Bot) handleAPIStarbase(w http.ResponseWriter, r *http.Request) {
+	starbaseID, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, APIPrefix+"/starbases/"))
+	if err != nil || starbaseID <= 0 {
+		writeAPIError(w, http.StatusBadRequest, "Invalid POS ID")
+		return
+	}
+
+	starbases, err := b.retrieveStarbaseList()
+	if err != nil {
+		log.WithError(err).Warn("Failed to retrieve starbase list for API request")
+		writeAPIError(w, http.StatusBadGateway, "Failed to retrieve POSes")
+		return
+	}
+
+	found := false
+	for _, starbase := range starbases.Starbases {
+		if starbase.ID == starbaseID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		writeAPIError(w, http.StatusNotFound, "POS not found")
+		return
+	}
+
+	pos, err := b.getPOSFromStarbaseID(starbaseID)
+	if err != nil {
+		log.WithField("starbaseID", starbaseID).WithError(err).Warn("Failed to get POS for API request")
+		writeAPIError(w, http.StatusBadGateway, "Failed to retrieve POS")
+		return
+	}
+	pos.Monitored = b.isStarbaseMonitored(pos.ID)
+
+	starbase := newAPIStarbase(pos)
+	threshold := b.getFuelThreshold(pos)
+	starbase.Threshold = &threshold
+
+	ack, err := b.retrieveStarbaseAcknowledgement(pos.ID)
+	if err != nil && err != redis.ErrNil {
+		log.WithField("starbaseID", pos.ID).WithError(err).Warn("Failed to retrieve starbase acknowledgement for API request")
+	} else if ack != nil && ack.Expires.After(time.Now().UTC()) {
+		starbase.Acknowledgement = newAPIAcknowledgement(ack)
+	}
+
+	if b.isStarbaseIgnoredByConfig(pos.ID) {
+		starbase.Ignore = &APIStarbaseIgnore{Config: true}
+	} else {
+		ignore, err := b.retrieveStarbaseIgnore(pos.ID)
+		if err != nil && err != redis.ErrNil {
+			log.WithField("starbaseID", pos.ID).WithError(err).Warn("Failed to retrieve starbase ignore for API request")
+		} else if ignore != nil {
+			starbase.Ignore = &APIStarbaseIgnore{
+				UserName:  ignore.UserName,
+				Reason:    ignore.Reason,
+				IgnoredAt: ignore.IgnoredAt,
+				Expires:   ignore.Expires,
+			}
+		}
+	}
+
+	writeAPIJSON(w, http.StatusOK, map[string]interface{}{
+		"starbase": starbase,
+	})
+}
+
+func (b *Bot) handleAPIAlerts(w http.ResponseWriter, r *http.Request) {
+	alerts, err := b.getCurrentFuelAlerts()
+	if err != nil {
+		log.WithError(err).Warn("Failed to retrieve fuel alerts for API request")
+		writeAPIError(w, http.StatusBadGateway, "Failed to retrieve fuel alerts")
+		return
+	}
+
+	sort.SliceStable(alerts, func(i, j int) bool {
+		return alerts[i].Fuel.HoursRemaining < alerts[j].Fuel.HoursRemaining
+	})
+
+	response := make([]*APIAlert, 0)
+	for _, alert := range alerts {
+		response = append(response, &APIAlert{
+			StarbaseID:      alert.POS.ID,
+			LocationName:    alert.POS.LocationName,
+			OwnerName:       alert.POS.OwnerName,
+			FuelTypeID:      alert.Fuel.TypeID,
+			FuelTypeName:    alert.Fuel.TypeName,
+			HoursRemaining:  alert.Fuel.HoursRemaining,
+			Stage:           alert.Stage.Name,
+			Severity:        alert.Severity,
+			Acknowledgement: newAPIAcknowledgement(alert.Acknowledgement),
+		})
+	}
+
+	writeAPIJSON(w, http.StatusOK, map[string]interface{}{
+		"count":  len(response),
+		"alerts": response,
+	})
+}
+
+func (b *Bot) handleAPITimers(w http.ResponseWriter, r *http.Request) {
+	poses, err := b.getAllPOSes()
+	if err != nil {
+		log.WithError(err).Warn("Failed to retrieve POSes for API request")
+		writeAPIError(w, http.StatusBadGateway, "Failed to retrieve POSes")
+		return
+	}
+
+	timers := make([]*APITimer, 0)
+	for _, pos := range poses {
+		timer := &APITimer{
+			StarbaseID:   pos.ID,
+			LocationName: pos.LocationName,
+			OwnerName:    pos.OwnerName,
+		}
+
+		switch pos.State {
+		case eveapi.StarbaseStateReinforced:
+			timer.Type = APITimerReinforced
+			timer.Time = pos.StateTimestamp
+		case eveapi.StarbaseStateOnlining:
+			timer.Type = APITimerOnlining
+			timer.Time = pos.OnlineTimestamp
+		default:
+			continue
+		}
+
+		timers = append(timers, timer)
+	}
+
+	sort.SliceStable(timers, func(i, j int) bool {
+		return timers[i].Time.Before(timers[j].Time)
+	})
+
+	writeAPIJSON(w, http.StatusOK, map[string]interface{}{
+		"count":  len(timers),
+		"timers": timers,
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/MorpheusXAUT/eveapi"
 	"github.com/MorpheusXAUT/evesi"
+	"github.com/Sirupsen/logrus"
 	"github.com/bwmarrin/discordgo"
 	"github.com/garyburd/redigo/redis"
 	_ "github.com/go-sql-driver/mysql"
@@ -27,10 +28,20 @@ type Bot struct {
 	mysql   *sqlx.DB
 	redis   *redis.Pool
 
-	config    *Config
-	startTime time.Time
-	stop      chan bool
-	ticker    *time.Ticker
+	config         *Config
+	startTime      time.Time
+	stop           chan bool
+	ticker         *time.Ticker
+	apiKeyTicker   *time.Ticker
+	scheduleTicker *time.Ticker
+	reportSchedule *Schedule
+	quietHours     []*QuietHoursWindow
+	commands       *CommandRegistry
+	notifiers      []Notifier
+	webhook        *WebhookNotifier
+	email          *EmailNotifier
+	api            *http.Server
+	health         *HealthStatus
 }
 
 func NewBot(config *Config) (*Bot, error) {
@@ -38,10 +49,29 @@ func NewBot(config *Config) (*Bot, error) {
 		config:    config,
 		startTime: time.Now().UTC(),
 		stop:      make(chan bool, 1),
+		health:    &HealthStatus{},
 	}
 
 	var err error
 
+	bot.commands = bot.newCommandRegistry()
+	bot.notifiers = bot.newNotifiers()
+
+	if bot.config.Discord.Report.Enabled {
+		bot.reportSchedule, err = parseSchedule(bot.config.Discord.Report.Schedule)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to parse report schedule")
+		}
+	}
+
+	for _, quietHours := range bot.config.Discord.QuietHours.Windows {
+		window, err := parseQuietHours(quietHours, bot.config.Discord.ChannelID)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to parse quiet hours")
+		}
+		bot.quietHours = append(bot.quietHours, window)
+	}
+
 	log.Info("Initialising Redis connection")
 	redisOptions := make([]redis.DialOption, 0)
 	if len(bot.config.Redis.Password) > 0 {
@@ -70,6 +100,12 @@ func NewBot(config *Config) (*Bot, error) {
 		return nil, errors.Wrap(err, "Failed to ping Redis server")
 	}
 
+	err = bot.migrateLegacyNotifications()
+	if err != nil {
+		bot.redis.Close()
+		return nil, errors.Wrap(err, "Failed to migrate legacy notifications")
+	}
+
 	log.Info("Creating httpcache client")
 	transport := httpcache.NewTransport(httpredis.NewWithClient(bot.redis.Get()))
 	bot.http = &http.Client{
@@ -98,6 +134,24 @@ func NewBot(config *Config) (*Bot, error) {
 		return nil, errors.Wrap(err, "Failed to query EVE server status")
 	}
 
+	log.Info("Validating EVE API key")
+	keyInfo, err := bot.retrieveAPIKeyInfo()
+	if err != nil {
+		bot.redis.Close()
+		return nil, errors.Wrap(err, "Failed to retrieve EVE API key info")
+	}
+
+	err = validateAPIKeyInfo(keyInfo)
+	if err != nil {
+		bot.redis.Close()
+		return nil, errors.Wrap(err, "EVE API key cannot be used to monitor starbases")
+	}
+
+	log.WithFields(logrus.Fields{
+		"corporation": keyInfo.CorporationName,
+		"expires":     keyInfo.Expires,
+	}).Info("Validated EVE API key")
+
 	log.Info("Initialising MySQL connection")
 	bot.mysql, err = sqlx.Open("mysql", fmt.Sprintf("%s:%s@tcp(%s)/%s", bot.config.MySQL.Username, bot.config.MySQL.Password, bot.config.MySQL.Address, bot.config.MySQL.Database))
 	if err != nil {
@@ -139,9 +193,13 @@ func NewBot(config *Config) (*Bot, error) {
 		return nil, errors.Wrap(err, "Failed to create Discord session")
 	}
 
+	bot.discord.Identify.Intents = discordgo.IntentsGuilds | discordgo.IntentsGuildMessages | discordgo.IntentsGuildMessageReactions | discordgo.IntentsDirectMessages | discordgo.IntentMessageContent
+
 	bot.discord.AddHandler(bot.onDiscordReady)
 	bot.discord.AddHandler(bot.onDiscordGuildCreate)
 	bot.discord.AddHandler(bot.onDiscordMessageCreate)
+	bot.discord.AddHandler(bot.onDiscordMessageReactionAdd)
+	bot.discord.AddHandler(bot.onDiscordInteractionCreate)
 
 	err = bot.discord.Open()
 	if err != nil {
@@ -150,9 +208,16 @@ func NewBot(config *Config) (*Bot, error) {
 		return nil, errors.Wrap(err, "Failed to open Discord session")
 	}
 
+	if bot.config.API.Enabled {
+		bot.startAPIServer()
+	}
+
 	go bot.monitoringLoop()
 	bot.ticker = time.NewTicker(time.Second * time.Duration(bot.config.EVE.MonitorInterval))
+	bot.apiKeyTicker = time.NewTicker(time.Hour * 24)
+	bot.scheduleTicker = time.NewTicker(time.Minute)
 	go bot.checkStarbaseFuel() // trigger once to avoid having to wait MonitorInterval seconds first
+	go bot.checkAPIKey()
 
 	return bot, nil
 }
@@ -170,8 +235,16 @@ func (b *Bot) Shutdown() {
 	log.Info("Clean bot shutdown initiated")
 
 	b.ticker.Stop()
+	b.apiKeyTicker.Stop()
+	b.scheduleTicker.Stop()
 	b.stop <- true
 
+	b.stopAPIServer()
+
+	if b.webhook != nil {
+		b.webhook.Shutdown()
+	}
+
 	if b.config.Discord.Debug {
 		b.discord.ChannelMessageSend(b.config.Discord.ChannelID, ":robot: POSbot shutting down :skull_crossbones:")
 	}
@@ -192,6 +265,12 @@ func (b *Bot) monitoringLoop() {
 		case <-b.ticker.C:
 			b.checkStarbaseFuel()
 			break
+		case <-b.apiKeyTicker.C:
+			b.checkAPIKey()
+			break
+		case <-b.scheduleTicker.C:
+			b.runScheduledTasks()
+			break
 		}
 	}
 }
@@ -0,0 +1,629 @@
+package main
+
+import (
+	"fmt"
+	"github.com/Sirupsen/logrus"
+	"github.com/pkg/errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	CommandPrefix = "!pos"
+)
+
+type CommandPermission int
+
+const (
+	CommandPermissionUser CommandPermission = iota
+	CommandPermissionAdmin
+)
+
+type CommandArgumentType int
+
+const (
+	CommandArgumentStarbase CommandArgumentType = iota
+	CommandArgumentDays
+	CommandArgumentHours
+	CommandArgumentDuration
+	CommandArgumentText
+	CommandArgumentFlag
+)
+
+type CommandArgument struct {
+	Name        string
+	Type        CommandArgumentType
+	Description string
+	Required    bool
+	Named       bool
+	Choices     []string
+}
+
+func (a *CommandArgument) parse(value string) (interface{}, error) {
+	switch a.Type {
+	case CommandArgumentStarbase:
+		starbaseID, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || starbaseID <= 0 {
+			return nil, errors.Errorf("Invalid POS ID %q", value)
+		}
+		return int(starbaseID), nil
+	case CommandArgumentDays:
+		days, err := strconv.Atoi(strings.TrimSuffix(value, "d"))
+		if err != nil || days <= 0 {
+			return nil, errors.Errorf("Invalid number of days %q", value)
+		}
+		return days, nil
+	case CommandArgumentHours:
+		multiplier := 1
+		number := value
+		if strings.HasSuffix(value, "d") {
+			multiplier = 24
+			number = strings.TrimSuffix(value, "d")
+		} else if strings.HasSuffix(value, "h") {
+			number = strings.TrimSuffix(value, "h")
+		}
+		hours, err := strconv.Atoi(number)
+		if err != nil || hours <= 0 {
+			return nil, errors.Errorf("Invalid number of hours %q", value)
+		}
+		return hours * multiplier, nil
+	case CommandArgumentDuration:
+		duration, err := parseDuration(value)
+		if err != nil || duration <= 0 {
+			return nil, errors.Errorf("Invalid duration %q", value)
+		}
+		return duration, nil
+	case CommandArgumentFlag:
+		flag, err := strconv.ParseBool(value)
+		if err != nil {
+			return strings.EqualFold(value, a.Name), nil
+		}
+		return flag, nil
+	default:
+		if len(a.Choices) > 0 {
+			for _, choice := range a.Choices {
+				if strings.EqualFold(choice, value) {
+					return choice, nil
+				}
+			}
+			return nil, errors.Errorf("Invalid choice %q", value)
+		}
+		return value, nil
+	}
+}
+
+func (a *CommandArgument) usage() string {
+	var usage string
+	switch {
+	case a.Type == CommandArgumentFlag:
+		usage = a.Name
+	case a.Named:
+		usage = fmt.Sprintf("%s=%s", a.Name, strings.ToUpper(a.placeholder()))
+	default:
+		usage = a.placeholder()
+	}
+
+	if a.Required {
+		return usage
+	}
+	return fmt.Sprintf("[%s]", usage)
+}
+
+func (a *CommandArgument) placeholder() string {
+	switch a.Type {
+	case CommandArgumentStarbase:
+		return "POSID"
+	case CommandArgumentHours:
+		return "hours"
+	default:
+		if len(a.Choices) > 0 {
+			return strings.Join(a.Choices, "|")
+		}
+		return a.Name
+	}
+}
+
+type CommandArgumentError struct {
+	Argument *CommandArgument
+	Value    string
+	Missing  bool
+	Unknown  bool
+}
+
+func (e *CommandArgumentError) Error() string {
+	if e.Unknown {
+		return fmt.Sprintf("Unknown argument %q", e.Value)
+	}
+	if e.Missing {
+		return fmt.Sprintf("Missing argument %q", e.Argument.Name)
+	}
+	return fmt.Sprintf("Invalid value %q for argument %q", e.Value, e.Argument.Name)
+}
+
+type CommandReplyError struct {
+	Reply string
+	Err   error
+}
+
+func newCommandReplyError(reply string, err error) error {
+	return &CommandReplyError{Reply: reply, Err: err}
+}
+
+func (e *CommandReplyError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *CommandReplyError) Cause() error {
+	return e.Err
+}
+
+type CommandContext struct {
+	ChannelID   string
+	UserID      string
+	UserName    string
+	IsAdmin     bool
+	Arguments   map[string]interface{}
+	Reply       func(content string)
+	Fail        func(content string)
+	Acknowledge func()
+}
+
+func (c *CommandContext) Has(name string) bool {
+	_, ok := c.Arguments[name]
+	return ok
+}
+
+func (c *CommandContext) Int(name string) int {
+	value, _ := c.Arguments[name].(int)
+	return value
+}
+
+func (c *CommandContext) String(name string) string {
+	value, _ := c.Arguments[name].(string)
+	return value
+}
+
+func (c *CommandContext) Duration(name string) time.Duration {
+	value, _ := c.Arguments[name].(time.Duration)
+	return value
+}
+
+func (c *CommandContext) Bool(name string) bool {
+	value, _ := c.Arguments[name].(bool)
+	return value
+}
+
+type Command struct {
+	Name        string
+	Aliases     []string
+	Description string
+	Permission  CommandPermission
+	Arguments   []*CommandArgument
+	Handler     func(ctx *CommandContext) error
+}
+
+func (c *Command) Usage() string {
+	parts := []string{CommandPrefix, c.Name}
+	for _, arg := range c.Arguments {
+		parts = append(parts, arg.usage())
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func (c *Command) Argument(name string) *CommandArgument {
+	for _, arg := range c.Arguments {
+		if strings.EqualFold(arg.Name, name) {
+			return arg
+		}
+	}
+
+	return nil
+}
+
+func (c *Command) parseArguments(tokens []string) (map[string]interface{}, error) {
+	args := make(map[string]interface{})
+
+	positionalTokens := make([]string, 0)
+	for _, token := range tokens {
+		if kv := strings.SplitN(token, "=", 2); len(kv) == 2 {
+			if arg := c.Argument(kv[0]); arg != nil {
+				value, err := arg.parse(kv[1])
+				if err != nil {
+					return nil, &CommandArgumentError{Argument: arg, Value: kv[1]}
+				}
+				args[arg.Name] = value
+				continue
+			}
+		}
+		if arg := c.Argument(token); arg != nil && arg.Type == CommandArgumentFlag {
+			args[arg.Name] = true
+			continue
+		}
+		positionalTokens = append(positionalTokens, token)
+	}
+
+	positional := make([]*CommandArgument, 0)
+	for _, arg := range c.Arguments {
+		if !arg.Named && arg.Type != CommandArgumentFlag {
+			positional = append(positional, arg)
+		}
+	}
+
+	index := 0
+	for i, arg := range positional {
+		if index >= len(positionalTokens) {
+			break
+		}
+
+		if arg.Type == CommandArgumentText {
+			rest := positionalTokens[index:]
+			later := positional[i+1:]
+
+			tail := 0
+			for j := len(later) - 1; j >= 0 && tail < len(rest); j-- {
+				if _, err := later[j].parse(rest[len(rest)-1-tail]); err != nil {
+					break
+				}
+				tail++
+			}
+
+			if text := strings.Join(rest[:len(rest)-tail], " "); len(text) > 0 {
+				args[arg.Name] = text
+			}
+			index += len(rest) - tail
+			continue
+		}
+
+		value, err := arg.parse(positionalTokens[index])
+		if err != nil {
+			// optional arguments may be left out if the remaining tokens fit the following ones, e.g. "refuels 7d"
+			if !arg.Required && len(positionalTokens)-index < len(positional)-i {
+				continue
+			}
+			return nil, &CommandArgumentError{Argument: arg, Value: positionalTokens[index]}
+		}
+		args[arg.Name] = value
+		index++
+	}
+
+	if index < len(positionalTokens) {
+		return nil, &CommandArgumentError{Value: positionalTokens[index], Unknown: true}
+	}
+
+	for _, arg := range c.Arguments {
+		if _, ok := args[arg.Name]; arg.Required && !ok {
+			return nil, &CommandArgumentError{Argument: arg, Missing: true}
+		}
+	}
+
+	return args, nil
+}
+
+type CommandRegistry struct {
+	commands []*Command
+	lookup   map[string]*Command
+}
+
+func NewCommandRegistry() *CommandRegistry {
+	return &CommandRegistry{
+		commands: make([]*Command, 0),
+		lookup:   make(map[string]*Command),
+	}
+}
+
+func (r *CommandRegistry) Register(command *Command) {
+	r.commands = append(r.commands, command)
+	r.lookup[strings.ToLower(command.Name)] = command
+	for _, alias := range command.Aliases {
+		r.lookup[strings.ToLower(alias)] = command
+	}
+}
+
+func (r *CommandRegistry) Find(name string) *Command {
+	return r.lookup[strings.ToLower(name)]
+}
+
+func (r *CommandRegistry) Commands() []*Command {
+	return r.commands
+}
+
+func (b *Bot) executeCommand(command *Command, ctx *CommandContext, args map[string]interface{}, argErr error) {
+	log.WithFields(logrus.Fields{
+		"author":  ctx.UserName,
+		"command": command.Name,
+		"isAdmin": ctx.IsAdmin,
+	}).Info("Processing POS Discord command")
+
+	if command.Permission == CommandPermissionAdmin && !ctx.IsAdmin {
+		log.WithFields(logrus.Fields{
+			"author":  ctx.UserName,
+			"command": command.Name,
+			"isAdmin": ctx.IsAdmin,
+		}).Info("Non-admin attempted to execute POS Discord command, ignoring")
+		b.recordCommandError(command.Name)
+		ctx.Reply("You don't have permission to do that :rage: I'll just be ignoring you, alright? :zipper_mouth:")
+		return
+	}
+
+	if argErr != nil {
+		log.WithFields(logrus.Fields{
+			"author":  ctx.UserName,
+			"command": command.Name,
+		}).WithError(argErr).Debug("Failed to parse arguments for POS Discord command")
+		b.recordCommandError(command.Name)
+		ctx.Reply(formatCommandArgumentError(command, argErr))
+		return
+	}
+
+	ctx.Arguments = args
+	if ctx.Acknowledge != nil {
+		ctx.Acknowledge()
+	}
+
+	err := command.Handler(ctx)
+	b.recordCommandResult(command.Name, err)
+	if replyErr, ok := err.(*CommandReplyError); ok {
+		ctx.Fail(replyErr.Reply)
+	}
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"author":  ctx.UserName,
+			"command": command.Name,
+		}).WithError(err).Debug("POS Discord command failed")
+	}
+
+	log.WithFields(logrus.Fields{
+		"author":  ctx.UserName,
+		"command": command.Name,
+		"isAdmin": ctx.IsAdmin,
+	}).Info("Processed POS Discord command")
+}
+
+func (b *Bot) recordCommandResult(command string, err error) {
+	if err != nil {
+		b.recordCommandError(command)
+		return
+	}
+
+	b.recordCommandUsage(command)
+}
+
+func formatCommandArgumentError(command *Command, err error) string {
+	argErr, ok := err.(*CommandArgumentError)
+	if !ok {
+		return fmt.Sprintf("Seems like there was an error processing this command :poop: Usage: `%s`", command.Usage())
+	}
+
+	if argErr.Unknown {
+		return fmt.Sprintf("I don't know what to do with %q :thinking: Usage: `%s`", argErr.Value, command.Usage())
+	}
+	if argErr.Missing {
+		return fmt.Sprintf("You'll have to tell me the %s (%s) :thinking: Usage: `%s`", argErr.Argument.usage(), argErr.Argument.Description, command.Usage())
+	}
+	return fmt.Sprintf("Seems like you've provided an invalid %s %q :poop: Usage: `%s`", argErr.Argument.placeholder(), argErr.Value, command.Usage())
+}
+
+func (b *Bot) newCommandRegistry() *CommandRegistry {
+	registry := NewCommandRegistry()
+
+	registry.Register(&Command{
+		Name:        "help",
+		Description: "Displays this help message",
+		Handler: func(ctx *CommandContext) error {
+			return b.handleDiscordPOSHelpCommand(ctx.ChannelID, ctx.UserID, ctx.IsAdmin)
+		},
+	})
+	registry.Register(&Command{
+		Name:        "list",
+		Description: "Lists all POSes, including ignored ones",
+		Arguments: append([]*CommandArgument{
+			{Name: "compact", Type: CommandArgumentFlag, Description: "one line per POS instead of paginated embeds"},
+		}, posFilterArguments()...),
+		Handler: func(ctx *CommandContext) error {
+			return b.handleDiscordPOSListCommand(ctx.ChannelID, ctx.UserID, parsePOSFilter(ctx), ctx.Bool("compact"))
+		},
+	})
+	registry.Register(&Command{
+		Name:        "fuel",
+		Aliases:     []string{"status"},
+		Description: "Shows an overview of fuel for monitored POSes",
+		Arguments: append([]*CommandArgument{
+			{Name: "compact", Type: CommandArgumentFlag, Description: "one line per POS instead of paginated embeds"},
+		}, posFilterArguments()...),
+		Handler: func(ctx *CommandContext) error {
+			filter := parsePOSFilter(ctx)
+			if filter.Monitored == nil {
+				monitored := true
+				filter.Monitored = &monitored
+			}
+			return b.handleDiscordPOSFuelCommand(ctx.ChannelID, ctx.UserID, filter, ctx.Bool("compact"))
+		},
+	})
+	registry.Register(&Command{
+		Name:        "details",
+		Aliases:     []string{"info"},
+		Description: "Tells you more about a specific POS",
+		Arguments: []*CommandArgument{
+			{Name: "starbase", Type: CommandArgumentStarbase, Description: "ID of the POS", Required: true},
+		},
+		Handler: func(ctx *CommandContext) error {
+			return b.handleDiscordPOSDetailsCommand(ctx.ChannelID, ctx.UserID, ctx.Int("starbase"))
+		},
+	})
+	registry.Register(&Command{
+		Name:        "plan",
+		Description: "Calculates the fuel required to keep every POS running",
+		Arguments: []*CommandArgument{
+			{Name: "days", Type: CommandArgumentDays, Description: "number of days to plan for"},
+		},
+		Handler: func(ctx *CommandContext) error {
+			days := b.config.Discord.Report.TopUpDays
+			if days <= 0 {
+				days = DefaultFuelPlanDays
+			}
+			if ctx.Has("days") {
+				days = ctx.Int("days")
+			}
+			return b.handleDiscordPOSPlanCommand(ctx.ChannelID, ctx.UserID, days)
+		},
+	})
+	registry.Register(&Command{
+		Name:        "ack",
+		Aliases:     []string{"acknowledge"},
+		Description: fmt.Sprintf("Acknowledges a fuel alert (reacting with %s works too)", DiscordEmojiAcknowledge),
+		Arguments: []*CommandArgument{
+			{Name: "starbase", Type: CommandArgumentStarbase, Description: "ID of the POS you're taking care of", Required: true},
+			{Name: "duration", Type: CommandArgumentDuration, Description: "how long to stay quiet, e.g. 6h or 2d"},
+		},
+		Handler: func(ctx *CommandContext) error {
+			duration := time.Duration(b.config.Discord.Notifications.Acknowledge) * time.Second
+			if ctx.Has("duration") {
+				duration = ctx.Duration("duration")
+			}
+			if duration < time.Second {
+				ctx.Reply("An acknowledgement has to last at least a second :thinking:")
+				return errors.New("Invalid acknowledgement duration")
+			}
+			return b.handleDiscordPOSAckCommand(ctx.ChannelID, ctx.UserID, ctx.UserName, ctx.Int("starbase"), duration)
+		},
+	})
+	registry.Register(&Command{
+		Name:        "refuels",
+		Aliases:     []string{"refuel"},
+		Description: fmt.Sprintf("Lists detected refuels of the last %d days", DefaultRefuelDays),
+		Arguments: []*CommandArgument{
+			{Name: "starbase", Type: CommandArgumentStarbase, Description: "ID of the POS"},
+			{Name: "days", Type: CommandArgumentDays, Description: "number of days to look back, e.g. 30 or 30d"},
+		},
+		Handler: func(ctx *CommandContext) error {
+			starbaseID := ctx.Int("starbase")
+			days := DefaultRefuelDays
+			if ctx.Has("days") {
+				days = ctx.Int("days")
+			} else if starbaseID > 0 && starbaseID <= RefuelRetentionDays && !b.isKnownStarbaseID(starbaseID) {
+				// a single small number that isn't a POS ID is the number of days
+				days = starbaseID
+				starbaseID = 0
+			}
+			return b.handleDiscordPOSRefuelsCommand(ctx.ChannelID, ctx.UserID, starbaseID, days)
+		},
+	})
+	registry.Register(&Command{
+		Name:        "subscribe",
+		Description: "Sends you direct messages for fuel alerts of a POS, corporation or region",
+		Arguments: []*CommandArgument{
+			{Name: "target", Type: CommandArgumentText, Description: "POS ID, corp NAME or region NAME", Required: true},
+			{Name: "severity", Type: CommandArgumentText, Description: "only notify for critical alerts or all of them", Choices: []string{SubscriptionSeverityWarning, SubscriptionSeverityCritical}},
+		},
+		Handler: func(ctx *CommandContext) error {
+			return b.handleDiscordPOSSubscribeCommand(ctx.ChannelID, ctx.UserID, ctx.String("target"), ctx.String("severity"))
+		},
+	})
+	registry.Register(&Command{
+		Name:        "unsubscribe",
+		Description: "Stops direct messages for a subscription, or all of them if no target is given",
+		Arguments: []*CommandArgument{
+			{Name: "target", Type: CommandArgumentText, Description: "POS ID, corp NAME or region NAME"},
+		},
+		Handler: func(ctx *CommandContext) error {
+			return b.handleDiscordPOSUnsubscribeCommand(ctx.ChannelID, ctx.UserID, ctx.String("target"))
+		},
+	})
+	registry.Register(&Command{
+		Name:        "subscriptions",
+		Description: "Lists your direct message subscriptions",
+		Handler: func(ctx *CommandContext) error {
+			return b.handleDiscordPOSSubscriptionsCommand(ctx.ChannelID, ctx.UserID)
+		},
+	})
+	registry.Register(&Command{
+		Name:        "ignore",
+		Description: "Stops monitoring a POS, optionally for a limited time",
+		Permission:  CommandPermissionAdmin,
+		Arguments: []*CommandArgument{
+			{Name: "starbase", Type: CommandArgumentStarbase, Description: "ID of the POS to ignore", Required: true},
+			{Name: "reason", Type: CommandArgumentText, Description: "why the POS should be ignored"},
+			{Name: "duration", Type: CommandArgumentDuration, Description: "how long to ignore the POS, e.g. 3d"},
+		},
+		Handler: func(ctx *CommandContext) error {
+			return b.handleDiscordPOSIgnoreCommand(ctx.ChannelID, ctx.UserID, ctx.UserName, ctx.Int("starbase"), ctx.String("reason"), ctx.Duration("duration"))
+		},
+	})
+	registry.Register(&Command{
+		Name:        "unignore",
+		Description: "Resumes monitoring an ignored POS",
+		Permission:  CommandPermissionAdmin,
+		Arguments: []*CommandArgument{
+			{Name: "starbase", Type: CommandArgumentStarbase, Description: "ID of the POS to watch again", Required: true},
+		},
+		Handler: func(ctx *CommandContext) error {
+			return b.handleDiscordPOSUnignoreCommand(ctx.ChannelID, ctx.UserID, ctx.Int("starbase"))
+		},
+	})
+	registry.Register(&Command{
+		Name:        "threshold",
+		Description: "Changes the fuel thresholds of a POS or resets them to the configured ones",
+		Permission:  CommandPermissionAdmin,
+		Arguments: []*CommandArgument{
+			{Name: "starbase", Type: CommandArgumentStarbase, Description: "ID of the POS", Required: true},
+			{Name: "warning", Type: CommandArgumentHours, Description: "warning threshold in hours", Named: true},
+			{Name: "critical", Type: CommandArgumentHours, Description: "critical threshold in hours", Named: true},
+			{Name: "reset", Type: CommandArgumentFlag, Description: "restore the configured thresholds"},
+		},
+		Handler: func(ctx *CommandContext) error {
+			if ctx.Bool("reset") {
+				return b.handleDiscordPOSThresholdResetCommand(ctx.ChannelID, ctx.UserID, ctx.Int("starbase"))
+			}
+
+			threshold := &FuelThreshold{
+				Warning:  ctx.Int("warning"),
+				Critical: ctx.Int("critical"),
+			}
+			if threshold.Warning <= 0 && threshold.Critical <= 0 {
+				ctx.Reply("You'll have to provide at least one of `warning=HOURS` or `critical=HOURS` :thinking:")
+				return errors.New("Missing thresholds")
+			}
+
+			return b.handleDiscordPOSThresholdCommand(ctx.ChannelID, ctx.UserID, ctx.Int("starbase"), threshold)
+		},
+	})
+	registry.Register(&Command{
+		Name:        "deadletters",
+		Description: "Lists webhook events that could not be delivered",
+		Permission:  CommandPermissionAdmin,
+		Arguments: []*CommandArgument{
+			{Name: "clear", Type: CommandArgumentFlag, Description: "remove all dead-lettered events"},
+		},
+		Handler: func(ctx *CommandContext) error {
+			return b.handleDiscordPOSDeadLettersCommand(ctx.ChannelID, ctx.UserID, ctx.Bool("clear"))
+		},
+	})
+	registry.Register(&Command{
+		Name:        "stats",
+		Description: "Displays performance stats",
+		Permission:  CommandPermissionAdmin,
+		Handler: func(ctx *CommandContext) error {
+			return b.handleDiscordPOSStatsCommand(ctx.ChannelID, ctx.UserID)
+		},
+	})
+	registry.Register(&Command{
+		Name:        "restart",
+		Description: "Restarts the bot",
+		Permission:  CommandPermissionAdmin,
+		Handler: func(ctx *CommandContext) error {
+			return b.handleDiscordPOSRestartCommand(ctx.ChannelID, ctx.UserID)
+		},
+	})
+	registry.Register(&Command{
+		Name:        "shutdown",
+		Description: "Shuts the bot down completely",
+		Permission:  CommandPermissionAdmin,
+		Handler: func(ctx *CommandContext) error {
+			return b.handleDiscordPOSShutdownCommand(ctx.ChannelID, ctx.UserID)
+		},
+	})
+
+	return registry
+}
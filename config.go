@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"github.com/pkg/errors"
 	"os"
+	"strings"
 )
 
 type Config struct {
@@ -26,20 +27,95 @@ type Config struct {
 		Verbose        bool   `json:"verbose"`
 		Debug          bool   `json:"debug"`
 		Notifications  struct {
-			Warning  int `json:"warning"`
-			Critical int `json:"critical"`
+			Warning     int `json:"warning"`
+			Critical    int `json:"critical"`
+			Acknowledge int `json:"acknowledge"`
 		} `json:"notifications"`
+		Escalation    []EscalationStage `json:"escalation"`
+		BatchAlerts   bool              `json:"batchAlerts"`
+		SlashCommands bool              `json:"slashCommands"`
+		Pagination    struct {
+			PageSize int `json:"pageSize"`
+			Timeout  int `json:"timeout"`
+		} `json:"pagination"`
+		QuietHours struct {
+			Windows       []QuietHours `json:"windows"`
+			CriticalFloor int          `json:"criticalFloor"`
+		} `json:"quietHours"`
+		Report struct {
+			Enabled   bool   `json:"enabled"`
+			Schedule  string `json:"schedule"`
+			ChannelID string `json:"channelID"`
+			TopUpDays int    `json:"topUpDays"`
+		} `json:"report"`
 	} `json:"discord"`
 	EVE struct {
 		KeyID            string `json:"keyID"`
 		KeyVCode         string `json:"keyvCode"`
 		IgnoredStarbases []int  `json:"ignoredStarbases"`
 		MonitorInterval  int    `json:"monitorInterval"`
+		KeyExpiryWarning int    `json:"keyExpiryWarning"`
 		FuelThreshold    struct {
-			Warning  int `json:"warning"`
-			Critical int `json:"critical"`
+			Warning   int                     `json:"warning"`
+			Critical  int                     `json:"critical"`
+			Overrides []FuelThresholdOverride `json:"overrides"`
 		} `json:"fuelThreshold"`
+		Pricing struct {
+			Enabled       bool   `json:"enabled"`
+			Source        string `json:"source"`
+			RegionID      int    `json:"regionID"`
+			LocationID    int    `json:"locationID"`
+			CacheDuration int    `json:"cacheDuration"`
+		} `json:"pricing"`
 	} `json:"eve"`
+	Notifiers struct {
+		Slack struct {
+			Enabled    bool   `json:"enabled"`
+			WebhookURL string `json:"webhookURL"`
+			Severity   string `json:"severity"`
+		} `json:"slack"`
+		Webhook struct {
+			Enabled     bool     `json:"enabled"`
+			URLs        []string `json:"urls"`
+			Secret      string   `json:"secret"`
+			MaxAttempts int      `json:"maxAttempts"`
+			Backoff     int      `json:"backoff"`
+		} `json:"webhook"`
+		Email struct {
+			Enabled    bool             `json:"enabled"`
+			Address    string           `json:"address"`
+			Username   string           `json:"username"`
+			Password   string           `json:"password"`
+			From       string           `json:"from"`
+			Security   string           `json:"security"`
+			Recipients []EmailRecipient `json:"recipients"`
+		} `json:"email"`
+		Telegram struct {
+			Enabled bool            `json:"enabled"`
+			BaseURL string          `json:"baseURL"`
+			Token   string          `json:"token"`
+			Routes  []TelegramRoute `json:"routes"`
+		} `json:"telegram"`
+		Matrix struct {
+			Enabled     bool          `json:"enabled"`
+			BaseURL     string        `json:"baseURL"`
+			AccessToken string        `json:"accessToken"`
+			Routes      []MatrixRoute `json:"routes"`
+		} `json:"matrix"`
+	} `json:"notifiers"`
+	API struct {
+		Enabled   bool     `json:"enabled"`
+		Address   string   `json:"address"`
+		Tokens    []string `json:"tokens"`
+		Dashboard struct {
+			Enabled bool     `json:"enabled"`
+			Refresh int      `json:"refresh"`
+			Tokens  []string `json:"tokens"`
+		} `json:"dashboard"`
+		Metrics struct {
+			Enabled bool `json:"enabled"`
+		} `json:"metrics"`
+	} `json:"api"`
 	Redis struct {
 		Address  string `json:"address"`
 		Password string `json:"password"`
@@ -53,6 +129,15 @@ type Config struct {
 	} `json:"mysql"`
 }
 
+type FuelThresholdOverride struct {
+	StarbaseID    int    `json:"starbaseID"`
+	CorporationID int    `json:"corporationID"`
+	Region        string `json:"region"`
+	Size          string `json:"size"`
+	Warning       int    `json:"warning"`
+	Critical      int    `json:"critical"`
+}
+
 func parseConfigFile(configFile string) (*Config, error) {
 	if _, err := os.Stat(configFile); os.IsNotExist(err) {
 		return nil, errors.Wrap(err, "Config file does not exist")
@@ -76,6 +161,154 @@ func parseConfigFile(configFile string) (*Config, error) {
 	if len(config.EVE.KeyID) == 0 || len(config.EVE.KeyVCode) == 0 {
 		return nil, errors.New("EVE config missing required data")
 	}
+	if config.EVE.KeyExpiryWarning <= 0 {
+		config.EVE.KeyExpiryWarning = DefaultKeyExpiryWarning
+	}
+	for _, override := range config.EVE.FuelThreshold.Overrides {
+		if override.StarbaseID <= 0 && override.CorporationID <= 0 && len(override.Region) == 0 && len(override.Size) == 0 {
+			return nil, errors.New("EVE fuel threshold override missing starbase, corporation, region or size")
+		}
+		warning, critical := config.EVE.FuelThreshold.Warning, config.EVE.FuelThreshold.Critical
+		if override.Warning > 0 {
+			warning = override.Warning
+		}
+		if override.Critical > 0 {
+			critical = override.Critical
+		}
+		if warning <= critical {
+			return nil, errors.Errorf("EVE fuel threshold override warning threshold (%dh) must be higher than critical one (%dh)", warning, critical)
+		}
+	}
+	if config.EVE.Pricing.Enabled {
+		if len(config.EVE.Pricing.Source) == 0 {
+			config.EVE.Pricing.Source = PriceSourceOrders
+		}
+		if !strings.EqualFold(config.EVE.Pricing.Source, PriceSourceOrders) && !strings.EqualFold(config.EVE.Pricing.Source, PriceSourceAverage) {
+			return nil, errors.Errorf("EVE pricing source %q is unknown", config.EVE.Pricing.Source)
+		}
+		if config.EVE.Pricing.RegionID <= 0 {
+			config.EVE.Pricing.RegionID = DefaultPriceRegionID
+		}
+		if config.EVE.Pricing.CacheDuration <= 0 {
+			config.EVE.Pricing.CacheDuration = DefaultPriceCacheTime
+		}
+	}
+	if config.Discord.Notifications.Acknowledge <= 0 {
+		config.Discord.Notifications.Acknowledge = DefaultAcknowledgeDuration
+	}
+	if len(config.Discord.Escalation) == 0 {
+		config.Discord.Escalation = defaultEscalationStages(config)
+	}
+	for _, stage := range config.Discord.Escalation {
+		if len(stage.Name) == 0 || stage.Repeat <= 0 {
+			return nil, errors.New("Discord escalation stage missing name or repeat interval")
+		}
+		if stage.HoursRemaining <= 0 && !strings.EqualFold(stage.Threshold, EscalationThresholdWarning) && !strings.EqualFold(stage.Threshold, EscalationThresholdCritical) {
+			return nil, errors.New("Discord escalation stage requires either hoursRemaining or a warning/critical threshold")
+		}
+	}
+	thresholds := []FuelThreshold{{Warning: config.EVE.FuelThreshold.Warning, Critical: config.EVE.FuelThreshold.Critical}}
+	for _, override := range config.EVE.FuelThreshold.Overrides {
+		threshold := thresholds[0]
+		if override.Warning > 0 {
+			threshold.Warning = override.Warning
+		}
+		if override.Critical > 0 {
+			threshold.Critical = override.Critical
+		}
+		thresholds = append(thresholds, threshold)
+	}
+	for _, threshold := range thresholds {
+		if err = validateEscalationStages(config.Discord.Escalation, threshold); err != nil {
+			return nil, errors.Wrapf(err, "Discord escalation stages are out of order for thresholds %dh/%dh", threshold.Warning, threshold.Critical)
+		}
+	}
+	if config.Discord.Pagination.PageSize <= 0 || config.Discord.Pagination.PageSize > DiscordMessageMaxEmbeds {
+		config.Discord.Pagination.PageSize = DefaultPageSize
+	}
+	if config.Discord.Pagination.Timeout <= 0 {
+		config.Discord.Pagination.Timeout = DefaultPageTimeout
+	}
+	for _, quietHours := range config.Discord.QuietHours.Windows {
+		if _, err = parseQuietHours(quietHours, config.Discord.ChannelID); err != nil {
+			return nil, errors.Wrap(err, "Discord quiet hours are invalid")
+		}
+	}
+	if config.Discord.Report.Enabled {
+		if _, err = parseSchedule(config.Discord.Report.Schedule); err != nil {
+			return nil, errors.Wrap(err, "Discord report schedule is invalid")
+		}
+		if config.Discord.Report.TopUpDays <= 0 {
+			return nil, errors.New("Discord report missing top up days")
+		}
+	}
+	if config.Notifiers.Slack.Enabled && len(config.Notifiers.Slack.WebhookURL) == 0 {
+		return nil, errors.New("Slack notifier missing webhook URL")
+	}
+	if config.Notifiers.Webhook.Enabled {
+		if len(config.Notifiers.Webhook.URLs) == 0 || len(config.Notifiers.Webhook.Secret) == 0 {
+			return nil, errors.New("Webhook notifier missing URLs or secret")
+		}
+		if config.Notifiers.Webhook.MaxAttempts <= 0 {
+			config.Notifiers.Webhook.MaxAttempts = DefaultWebhookMaxAttempts
+		}
+		if config.Notifiers.Webhook.Backoff <= 0 {
+			config.Notifiers.Webhook.Backoff = DefaultWebhookBackoff
+		}
+	}
+	if config.Notifiers.Email.Enabled {
+		if len(config.Notifiers.Email.Address) == 0 || len(config.Notifiers.Email.From) == 0 || len(config.Notifiers.Email.Recipients) == 0 {
+			return nil, errors.New("Email notifier missing address, sender or recipients")
+		}
+		if len(config.Notifiers.Email.Security) == 0 {
+			config.Notifiers.Email.Security = EmailSecuritySTARTTLS
+		}
+		if !strings.EqualFold(config.Notifiers.Email.Security, EmailSecurityNone) && !strings.EqualFold(config.Notifiers.Email.Security, EmailSecuritySTARTTLS) && !strings.EqualFold(config.Notifiers.Email.Security, EmailSecurityTLS) {
+			return nil, errors.Errorf("Email notifier security %q is unknown", config.Notifiers.Email.Security)
+		}
+		for i := range config.Notifiers.Email.Recipients {
+			if len(config.Notifiers.Email.Recipients[i].Address) == 0 {
+				return nil, errors.New("Email notifier recipient missing address")
+			}
+			if len(config.Notifiers.Email.Recipients[i].Severity) == 0 {
+				config.Notifiers.Email.Recipients[i].Severity = AlertSeverityCritical
+			}
+		}
+	}
+	if config.Notifiers.Telegram.Enabled {
+		if len(config.Notifiers.Telegram.Token) == 0 || len(config.Notifiers.Telegram.Routes) == 0 {
+			return nil, errors.New("Telegram notifier missing token or routes")
+		}
+		if len(config.Notifiers.Telegram.BaseURL) == 0 {
+			config.Notifiers.Telegram.BaseURL = DefaultTelegramBaseURL
+		}
+		for _, route := range config.Notifiers.Telegram.Routes {
+			if len(route.ChatID) == 0 {
+				return nil, errors.New("Telegram notifier route missing chat ID")
+			}
+		}
+	}
+	if config.Notifiers.Matrix.Enabled {
+		if len(config.Notifiers.Matrix.BaseURL) == 0 || len(config.Notifiers.Matrix.AccessToken) == 0 || len(config.Notifiers.Matrix.Routes) == 0 {
+			return nil, errors.New("Matrix notifier missing homeserver base URL, access token or routes")
+		}
+		for _, route := range config.Notifiers.Matrix.Routes {
+			if len(route.RoomID) == 0 {
+				return nil, errors.New("Matrix notifier route missing room ID")
+			}
+		}
+	}
+	if config.API.Enabled {
+		if len(config.API.Address) == 0 {
+			config.API.Address = DefaultAPIAddress
+		}
+		if len(config.API.Tokens) == 0 {
+			return nil, errors.New("API config missing tokens")
+		}
+		if config.API.Dashboard.Enabled && len(config.API.Dashboard.Tokens) == 0 {
+			return nil, errors.New("API config missing dashboard tokens")
+		}
+	}
 	if len(config.Redis.Address) == 0 {
 		return nil, errors.New("Redis config missing required data")
 	}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"fmt"
+	"github.com/MorpheusXAUT/eveapi"
+	"github.com/Sirupsen/logrus"
+	"html/template"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	DashboardPath            = "/dashboard"
+	DashboardDefaultRefresh  = 60
+	DashboardMinRefresh      = 10
+	DashboardFuelBarHours    = 30 * 24
+	DashboardRecentAlerts    = 25
+	DashboardTokenQueryParam = "token"
+	DashboardTokenCookie     = "posbot_dashboard"
+	DashboardCookieMaxAge    = 30 * 24 * 60 * 60
+	DashboardStatusOK        = "ok"
+	DashboardStatusWarning   = "warning"
+	DashboardStatusCritical  = "critical"
+	DashboardStatusUnfuelled = "unfuelled"
+	DashboardStatusUnknown   = "unknown"
+	DashboardTimestampFormat = "2006-01-02 15:04 MST"
+)
+
+type Dashboard struct {
+	GeneratedAt  time.Time
+	Refresh      int
+	RefreshURL   string
+	Starbases    []*DashboardStarbase
+	Total        int
+	Warning      int
+	Critical     int
+	RecentAlerts []*AlertHistoryEntry
+	Error        string
+}
+
+type DashboardStarbase struct {
+	POS            *POS
+	Status         string
+	Color          string
+	StateColor     string
+	FuelPercent    int
+	HoursRemaining float64
+	TimeToEmpty    string
+	EmptyAt        time.Time
+}
+
+var dashboardTemplate = template.Must(template.New("dashboard").Funcs(template.FuncMap{
+	"timestamp": func(t time.Time) string { return t.UTC().Format(DashboardTimestampFormat) },
+	"hours":     formatHoursRemaining,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta http-equiv="refresh" content="{{.Refresh}}; url={{.RefreshURL}}">
+<title>POSbot - fuel status</title>
+<style>
+body { background: #2f3136; color: #dcddde; font-family: sans-serif; margin: 2em; }
+h1, h2 { color: #ffffff; font-weight: normal; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 2em; }
+th { text-align: left; border-bottom: 1px solid #4f545c; padding: 6px; }
+td { padding: 6px; border-bottom: 1px solid #40444b; vertical-align: middle; }
+.bar { background: #40444b; border-radius: 3px; height: 14px; width: 200px; }
+.bar div { border-radius: 3px; height: 14px; }
+.summary span { margin-right: 2em; }
+.muted { color: #8e9297; }
+.error { color: #e50d0d; }
+</style>
+</head>
+<body>
+<h1>POS fuel status</h1>
+<p class="summary"><span>{{.Total}} POSes</span><span style="color: #ffa500;">{{.Warning}} warning</span><span style="color: #e50d0d;">{{.Critical}} critical</span><span class="muted">generated {{timestamp .GeneratedAt}}, refreshing every {{.Refresh}}s</span></p>
+{{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+<table>
+<tr><th>Location</th><th>Region</th><th>Owner</th><th>Size</th><th>State</th><th>Fuel</th><th>Time to empty</th><th>Empty at</th></tr>
+{{range .Starbases}}<tr>
+<td>{{.POS.LocationName}}{{if not .POS.Monitored}} <span class="muted">(ignored)</span>{{end}}</td>
+<td>{{.POS.RegionName}}</td>
+<td>{{.POS.OwnerName}}</td>
+<td>{{.POS.Size}}</td>
+<td style="color: {{.StateColor}};">{{.POS.State}}</td>
+<td><div class="bar" title="{{.Status}}"><div style="width: {{.FuelPercent}}%; background: {{.Color}};"></div></div></td>
+<td style="color: {{.Color}};">{{.TimeToEmpty}}</td>
+<td class="muted">{{if .EmptyAt.IsZero}}-{{else}}{{timestamp .EmptyAt}}{{end}}</td>
+</tr>
+{{else}}<tr><td colspan="8" class="muted">No POSes found.</td></tr>
+{{end}}</table>
+<h2>Recent alerts</h2>
+<table>
+<tr><th>Time</th><th>Location</th><th>Owner</th><th>Fuel</th><th>Remaining</th><th>Stage</th></tr>
+{{range .RecentAlerts}}<tr{{if eq .Severity "critical"}} style="color: #e50d0d;"{{else}} style="color: #ffa500;"{{end}}>
+<td>{{timestamp .Timestamp}}</td>
+<td>{{.LocationName}}</td>
+<td>{{.OwnerName}}</td>
+<td>{{.FuelTypeName}}</td>
+<td>{{.Remaining}}</td>
+<td>{{.Stage}}</td>
+</tr>
+{{else}}<tr><td colspan="6" class="muted">No recent alerts.</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+func formatDashboardColor(color int) string {
+	return fmt.Sprintf("#%06x", color)
+}
+
+func getDashboardFuelStatus(pos *POS, threshold FuelThreshold) (string, int) {
+	status := DashboardStatusOK
+	color := DiscordEmbedColorGreen
+
+	for _, fuel := range pos.Fuel {
+		if !fuel.ConstantlyRequired {
+			continue
+		}
+
+		if int(fuel.HoursRemaining) <= threshold.Critical {
+			return DashboardStatusCritical, DiscordEmbedColorRed
+		} else if int(fuel.HoursRemaining) <= threshold.Warning {
+			status = DashboardStatusWarning
+			color = DiscordEmbedColorOrange
+		}
+	}
+
+	return status, color
+}
+
+func (b *Bot) newDashboardStarbase(pos *POS) *DashboardStarbase {
+	stateColor, _ := formatStarbaseStateForDiscord(pos.State)
+	starbase := &DashboardStarbase{
+		POS:            pos,
+		StateColor:     formatDashboardColor(stateColor),
+		HoursRemaining: pos.HoursRemaining(),
+	}
+
+	if pos.State != eveapi.StarbaseStateOnline && pos.State != eveapi.StarbaseStateReinforced && pos.State != eveapi.StarbaseStateOnlining {
+		starbase.Status = DashboardStatusUnfuelled
+		starbase.Color = formatDashboardColor(DiscordEmbedColorWhite)
+		starbase.TimeToEmpty = "-"
+		return starbase
+	}
+
+	constantly := false
+	for _, fuel := range pos.Fuel {
+		if fuel.ConstantlyRequired {
+			constantly = true
+			break
+		}
+	}
+
+	if !constantly {
+		starbase.Status = DashboardStatusUnknown
+		starbase.Color = formatDashboardColor(DiscordEmbedColorBlue)
+		starbase.TimeToEmpty = "unknown"
+		return starbase
+	}
+
+	status, color := getDashboardFuelStatus(pos, b.getFuelThreshold(pos))
+	starbase.Status = status
+	starbase.Color = formatDashboardColor(color)
+	starbase.TimeToEmpty = formatHoursRemaining(starbase.HoursRemaining)
+	starbase.EmptyAt = time.Now().UTC().Add(time.Duration(starbase.HoursRemaining * float64(time.Hour)))
+	starbase.FuelPercent = int(math.Min(100, math.Max(0, starbase.HoursRemaining/DashboardFuelBarHours*100)))
+	if starbase.FuelPercent == 0 && starbase.HoursRemaining > 0 {
+		starbase.FuelPercent = 1
+	}
+
+	return starbase
+}
+
+func (b *Bot) authenticateDashboard(handler http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Referrer-Policy", "no-referrer")
+
+		query := r.URL.Query().Get(DashboardTokenQueryParam)
+		token := apiTokenFromRequest(r)
+		if len(token) == 0 {
+			token = query
+		}
+		if len(token) == 0 {
+			if cookie, err := r.Cookie(DashboardTokenCookie); err == nil {
+				token = cookie.Value
+			}
+		}
+
+		if !b.isValidDashboardToken(token) {
+			log.WithFields(logrus.Fields{
+				"path":   r.URL.Path,
+				"remote": r.RemoteAddr,
+			}).Warn("Rejected unauthenticated dashboard request")
+			http.Error(w, "Missing or invalid token, append ?token=TOKEN to the URL", http.StatusUnauthorized)
+			return
+		}
+
+		// move the token into a cookie so it doesn't stay in the address bar, history or refresh URL
+		if len(query) > 0 {
+			http.SetCookie(w, &http.Cookie{
+				Name:     DashboardTokenCookie,
+				Value:    query,
+				Path:     DashboardPath,
+				MaxAge:   DashboardCookieMaxAge,
+				Secure:   r.TLS != nil || strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https"),
+				HttpOnly: true,
+				SameSite: http.SameSiteStrictMode,
+			})
+			w.Header().Set("Cache-Control", "no-store")
+			http.Redirect(w, r, DashboardPath, http.StatusSeeOther)
+			return
+		}
+
+		handler(w, r)
+	})
+}
+
+func (b *Bot) getDashboardRefresh() int {
+	refresh := b.config.API.Dashboard.Refresh
+	if refresh <= 0 {
+		return DashboardDefaultRefresh
+	} else if refresh < DashboardMinRefresh {
+		return DashboardMinRefresh
+	}
+
+	return refresh
+}
+
+func (b *Bot) handleDashboard(w http.ResponseWriter, r *http.Request) {
+	dashboard := &Dashboard{
+		GeneratedAt: time.Now().UTC(),
+		Refresh:     b.getDashboardRefresh(),
+		RefreshURL:  DashboardPath,
+		Starbases:   make([]*DashboardStarbase, 0),
+	}
+
+	poses, err := b.getAllPOSes()
+	if err != nil {
+		log.WithError(err).Warn("Failed to retrieve POSes for dashboard")
+		dashboard.Error = "Failed to retrieve POSes, showing no data until the next refresh."
+	}
+
+	for _, pos := range poses {
+		starbase := b.newDashboardStarbase(pos)
+		if pos.Monitored {
+			switch starbase.Status {
+			case DashboardStatusWarning:
+				dashboard.Warning++
+			case DashboardStatusCritical:
+				dashboard.Critical++
+			}
+		}
+		dashboard.Starbases = append(dashboard.Starbases, starbase)
+	}
+	dashboard.Total = len(dashboard.Starbases)
+
+	sort.SliceStable(dashboard.Starbases, func(i, j int) bool {
+		return dashboard.Starbases[i].HoursRemaining < dashboard.Starbases[j].HoursRemaining
+	})
+
+	dashboard.RecentAlerts, err = b.retrieveAlertHistory(DashboardRecentAlerts)
+	if err != nil {
+		log.WithError(err).Warn("Failed to retrieve alert history for dashboard")
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	if err = dashboardTemplate.Execute(w, dashboard); err != nil {
+		log.WithError(err).Warn("Failed to render dashboard")
+	}
+}
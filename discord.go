@@ -7,11 +7,13 @@ import (
 	"github.com/Sirupsen/logrus"
 	"github.com/bwmarrin/discordgo"
 	"github.com/dustin/go-humanize"
+	"github.com/garyburd/redigo/redis"
 	"github.com/pkg/errors"
 	"github.com/shirou/gopsutil/cpu"
 	"github.com/shirou/gopsutil/host"
 	"github.com/shirou/gopsutil/load"
 	"github.com/shirou/gopsutil/mem"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -23,10 +25,22 @@ const (
 	DiscordEmbedColorOrange = 16750848
 	DiscordEmbedColorRed    = 15011085
 	DiscordEmbedColorWhite  = 16777215
+
+	DefaultFuelPlanDays = 14
+
+	DiscordEmbedMaxFields     = 25
+	DiscordMessageMaxLength   = 2000
+	DiscordTableLocationWidth = 36
+
+	DiscordEmojiAcknowledge = "✅"
 )
 
 func (b *Bot) onDiscordReady(s *discordgo.Session, event *discordgo.Ready) {
-	_ = s.UpdateStatus(0, "Starbase Online")
+	_ = s.UpdateGameStatus(0, "Starbase Online")
+
+	if b.config.Discord.SlashCommands {
+		b.registerDiscordApplicationCommands(s)
+	}
 }
 
 func (b *Bot) onDiscordGuildCreate(s *discordgo.Session, event *discordgo.GuildCreate) {
@@ -65,6 +79,45 @@ func (b *Bot) onDiscordMessageCreate(s *discordgo.Session, message *discordgo.Me
 	}
 }
 
+func (b *Bot) onDiscordMessageReactionAdd(s *discordgo.Session, event *discordgo.MessageReactionAdd) {
+	if event.UserID == s.State.User.ID {
+		return
+	}
+	if isDiscordEmoji(event.Emoji.Name, DiscordEmojiPreviousPage) {
+		b.flipDiscordPaginatedMessage(event, -1)
+		return
+	} else if isDiscordEmoji(event.Emoji.Name, DiscordEmojiNextPage) {
+		b.flipDiscordPaginatedMessage(event, 1)
+		return
+	} else if event.Emoji.Name != DiscordEmojiAcknowledge {
+		return
+	}
+
+	starbaseIDs, stage, err := b.retrieveAlertMessage(event.MessageID)
+	if err == redis.ErrNil {
+		return
+	} else if err != nil {
+		log.WithField("messageID", event.MessageID).WithError(err).Warn("Failed to retrieve alert message for reaction")
+		return
+	}
+
+	user, err := s.User(event.UserID)
+	if err != nil {
+		log.WithField("userID", event.UserID).WithError(err).Warn("Failed to retrieve user for reaction")
+		return
+	}
+
+	log.WithFields(logrus.Fields{
+		"author":      user.Username,
+		"starbaseIDs": starbaseIDs,
+	}).Info("Processing POS acknowledgement Discord reaction")
+	err = b.acknowledgeStarbases(event.ChannelID, user.ID, user.Username, starbaseIDs, stage, time.Duration(b.config.Discord.Notifications.Acknowledge)*time.Second)
+	if replyErr, ok := err.(*CommandReplyError); ok {
+		b.discord.ChannelMessageSend(event.ChannelID, replyErr.Reply)
+	}
+	b.recordCommandResult("ack", err)
+}
+
 func (b *Bot) getChannelFromMessage(message *discordgo.MessageCreate) (*discordgo.Channel, error) {
 	return b.discord.Channel(message.ChannelID)
 }
@@ -95,6 +148,14 @@ func (b *Bot) hasBotAdminRole(message *discordgo.MessageCreate) bool {
 		return false
 	}
 
+	return b.isBotAdminMember(member)
+}
+
+func (b *Bot) isBotAdminMember(member *discordgo.Member) bool {
+	if member == nil {
+		return false
+	}
+
 	admin := false
 	for _, role := range member.Roles {
 		if strings.EqualFold(role, b.config.Discord.BotAdminRoleID) {
@@ -122,287 +183,352 @@ func (b *Bot) isRelevantDiscordChannel(message *discordgo.MessageCreate) bool {
 func (b *Bot) handleDiscordPOSCommand(message *discordgo.MessageCreate) {
 	b.discord.ChannelTyping(message.ChannelID)
 
-	messageParts := strings.Split(message.Content, " ")
-	isAdmin := b.hasBotAdminRole(message)
-
-	if len(messageParts) == 1 || (len(messageParts) == 2 && strings.EqualFold(messageParts[1], "help")) {
-		log.WithField("author", message.Author.Username).Info("Processing POS help Discord command")
+	messageParts := strings.Fields(message.Content)
+	name := "help"
+	if len(messageParts) >= 2 {
+		name = messageParts[1]
+	}
 
-		monitored, err := b.getMonitoredStarbaseIDs()
-		if err != nil {
-			b.recordCommandError("help")
-			log.WithField("author", message.Author.Username).WithError(err).Warn("Failed to get monitored POS IDs")
-		}
+	command := b.commands.Find(name)
+	if command == nil {
+		log.WithFields(logrus.Fields{
+			"author":  message.Author.Username,
+			"command": name,
+		}).Debug("Received unknown POS Discord command")
+		b.discord.ChannelMessageSend(message.ChannelID, fmt.Sprintf("<@%s> seems to be drunk, there's no command like this :thinking:", message.Author.ID))
+		return
+	}
 
-		b.discord.ChannelMessageSend(message.ChannelID, fmt.Sprintf("Hey <@%s>, I'm **POSbot**, glad to meet you :slight_smile: I am keeping track of EVE Online POSes for you. At the moment, I'm monitoring %d POSes.", message.Author.ID, len(monitored)))
-		b.discord.ChannelMessageSend(message.ChannelID, "You can use various commands to query information about POS statuses, but I'll also shout at you if something is about to go wrong :smile:")
-		b.discord.ChannelMessageSend(message.ChannelID, "A list of POSes can be displayed via `!pos list`, `!pos fuel` will show an overview of fuel for monitored POSes. `!pos details POSID` tells you more about a specific starbase. `!pos` or `!pos help` displays this help message. That's about it for now!")
-		if isAdmin {
-			b.discord.ChannelMessageSend(message.ChannelID, "Oh wait, you're super \"important\" :nerd: You can also use `!pos stats` to display performance stats, `!pos restart` to restart the bot or `!pos shutdown` to shut it down completely :skull:")
-		}
+	ctx := &CommandContext{
+		ChannelID: message.ChannelID,
+		UserID:    message.Author.ID,
+		UserName:  message.Author.Username,
+		IsAdmin:   b.hasBotAdminRole(message),
+		Reply: func(content string) {
+			b.discord.ChannelMessageSend(message.ChannelID, fmt.Sprintf("<@%s>: %s", message.Author.ID, content))
+		},
+		Fail: func(content string) {
+			b.discord.ChannelMessageSend(message.ChannelID, content)
+		},
+	}
 
-		b.recordCommandUsage("help")
-		log.WithField("author", message.Author.Username).Info("Processed POS help Discord command")
-		return
-	} else if len(messageParts) >= 2 {
-		switch strings.ToLower(messageParts[1]) {
-		case "details":
-			log.WithField("author", message.Author.Username).Info("Processing POS details Discord command")
-			if len(messageParts) < 3 {
-				b.recordCommandError("details")
-				b.discord.ChannelMessageSend(message.ChannelID, fmt.Sprintf("<@%s>: You'll have to tell me which POS you want to know more about...", message.Author.ID))
-				return
-			}
+	var args map[string]interface{}
+	var err error
+	if len(messageParts) >= 2 {
+		args, err = command.parseArguments(messageParts[2:])
+	} else {
+		args, err = command.parseArguments(nil)
+	}
 
-			starbaseID, err := strconv.ParseInt(messageParts[2], 10, 64)
-			if err != nil || starbaseID <= 0 {
-				log.WithField("starbaseID", starbaseID).WithError(err).Debug("Failed to parse starbaseID for Discord POS details command")
-				b.recordCommandError("details")
-				b.discord.ChannelMessageSend(message.ChannelID, fmt.Sprintf("<@%s>: Seems like you've provided an invalid POS ID %q :poop:", message.Author.ID, messageParts[2]))
-				return
-			}
+	b.executeCommand(command, ctx, args, err)
+}
 
-			b.handleDiscordPOSDetailsCommand(message.ChannelID, message.Author.ID, int(starbaseID))
-			log.WithField("author", message.Author.Username).Info("Processed POS details Discord command")
-			return
-		case "fuel":
-			log.WithField("author", message.Author.Username).Info("Processing POS details Discord command")
-			b.handleDiscordPOSFuelCommand(message.ChannelID, message.Author.ID)
-			log.WithField("author", message.Author.Username).Info("Processed POS fuel Discord command")
-			return
-		case "list":
-			log.WithField("author", message.Author.Username).Info("Processing POS list Discord command")
-			b.handleDiscordPOSListCommand(message.ChannelID, message.Author.ID)
-			log.WithField("author", message.Author.Username).Info("Processed POS list Discord command")
-			return
-		case "restart":
-			log.WithFields(logrus.Fields{
-				"author":  message.Author.Username,
-				"isAdmin": isAdmin,
-			}).Info("Processing POS restart Discord command")
-			if !isAdmin {
-				log.WithFields(logrus.Fields{
-					"author":  message.Author.Username,
-					"isAdmin": isAdmin,
-				}).Info("Non-admin attempted to execute POS restart Discord command, ignoring")
-				b.recordCommandError("restart")
-				b.discord.ChannelMessageSend(message.ChannelID, fmt.Sprintf("You don't have permission to do that, <@%s> :rage: I'll just be ignoring you, alright? :zipper_mouth:", message.Author.ID))
-				return
-			}
+func (b *Bot) handleDiscordPOSHelpCommand(channelID string, userID string, isAdmin bool) error {
+	monitored, err := b.getMonitoredStarbaseIDs()
+	if err != nil {
+		log.WithField("userID", userID).WithError(err).Warn("Failed to get monitored POS IDs")
+	}
 
-			b.handleDiscordPOSRestartCommand(message.ChannelID, message.Author.ID)
-			log.WithFields(logrus.Fields{
-				"author":  message.Author.Username,
-				"isAdmin": isAdmin,
-			}).Info("Processed POS restart Discord command")
-			return
-		case "shutdown":
-			log.WithFields(logrus.Fields{
-				"author":  message.Author.Username,
-				"isAdmin": isAdmin,
-			}).Info("Processing POS shutdown Discord command")
-			if !isAdmin {
-				log.WithFields(logrus.Fields{
-					"author":  message.Author.Username,
-					"isAdmin": isAdmin,
-				}).Info("Non-admin attempted to execute POS shutdown Discord command, ignoring")
-				b.recordCommandError("shutdown")
-				b.discord.ChannelMessageSend(message.ChannelID, fmt.Sprintf("You don't have permission to do that, <@%s> :rage: I'll just be ignoring you, alright? :zipper_mouth:", message.Author.ID))
-				return
-			}
+	b.discord.ChannelMessageSend(channelID, fmt.Sprintf("Hey <@%s>, I'm **POSbot**, glad to meet you :slight_smile: I am keeping track of EVE Online POSes for you. At the moment, I'm monitoring %d POSes.", userID, len(monitored)))
+	b.discord.ChannelMessageSend(channelID, "You can use various commands to query information about POS statuses, but I'll also shout at you if something is about to go wrong :smile:")
 
-			b.handleDiscordPOSShutdownCommand(message.ChannelID, message.Author.ID)
-			log.WithFields(logrus.Fields{
-				"author":  message.Author.Username,
-				"isAdmin": isAdmin,
-			}).Info("Processed POS shutdown Discord command")
-			return
-		case "stats":
-			log.WithFields(logrus.Fields{
-				"author":  message.Author.Username,
-				"isAdmin": isAdmin,
-			}).Info("Processing POS stats Discord command")
-			if !isAdmin {
-				log.WithFields(logrus.Fields{
-					"author":  message.Author.Username,
-					"isAdmin": isAdmin,
-				}).Info("Non-admin attempted to execute POS stats Discord command, ignoring")
-				b.recordCommandError("stats")
-				b.discord.ChannelMessageSend(message.ChannelID, fmt.Sprintf("You don't have permission to do that, <@%s> :rage: I'll just be ignoring you, alright? :zipper_mouth:", message.Author.ID))
-				return
-			}
+	lines := make([]string, 0)
+	adminLines := make([]string, 0)
+	for _, command := range b.commands.Commands() {
+		line := fmt.Sprintf("`%s` - %s", command.Usage(), command.Description)
+		if len(command.Aliases) > 0 {
+			line = fmt.Sprintf("%s (*aliases*: %s)", line, strings.Join(command.Aliases, ", "))
+		}
 
-			b.handleDiscordPOSStatsCommand(message.ChannelID, message.Author.ID)
-			log.WithFields(logrus.Fields{
-				"author":  message.Author.Username,
-				"isAdmin": isAdmin,
-			}).Info("Processed POS stats Discord command")
-			return
+		if command.Permission == CommandPermissionAdmin {
+			adminLines = append(adminLines, line)
+		} else {
+			lines = append(lines, line)
 		}
 	}
 
-	b.discord.ChannelMessageSend(message.ChannelID, fmt.Sprintf("<@%s> seems to be drunk, there's no command like this :thinking:", message.Author.ID))
+	b.sendDiscordLines(channelID, lines)
+	if isAdmin && len(adminLines) > 0 {
+		b.discord.ChannelMessageSend(channelID, "Oh wait, you're super \"important\" :nerd: You can also use these commands:")
+		b.sendDiscordLines(channelID, adminLines)
+	}
+	if b.config.Discord.SlashCommands {
+		b.discord.ChannelMessageSend(channelID, "All of these commands are also available as `/pos` slash commands, including autocompletion for POS IDs :sparkles:")
+	}
+
+	return nil
 }
 
-func (b *Bot) handleDiscordPOSDetailsCommand(channelID string, userID string, starbaseID int) {
-	b.discord.ChannelMessageSend(channelID, "Not implemented yet :innocent:")
-	b.recordCommandError("details")
+func (b *Bot) handleDiscordPOSDetailsCommand(channelID string, userID string, starbaseID int) error {
+	b.discord.ChannelTyping(channelID)
+
+	pos, err := b.getPOSFromStarbaseID(starbaseID)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"userID":     userID,
+			"starbaseID": starbaseID,
+		}).WithError(err).Warn("Failed to get POS for Discord command")
+		return newCommandReplyError(fmt.Sprintf("<@%s>: I couldn't find any details for POS %d :thinking: Are you sure it exists?", userID, starbaseID), err)
+	}
+
+	embed, _ := b.formatPOSFuelEmbedForDiscord(pos, userID, fmt.Sprintf(":stars: POS %d", pos.ID))
+
+	b.discord.ChannelMessageSendEmbed(channelID, embed)
+	return nil
 }
 
-func (b *Bot) handleDiscordPOSFuelCommand(channelID string, userID string) {
+func (b *Bot) handleDiscordPOSFuelCommand(channelID string, userID string, filter *POSFilter, compact bool) error {
 	err := b.updateMonitoredStarbaseDetails()
 	if err != nil {
 		log.WithField("userID", userID).WithError(err).Warn("Failed to update monitored starbase details for Discord command")
-		b.recordCommandError("fuel")
-		b.discord.ChannelMessageSend(channelID, fmt.Sprintf("It appears like I can't update the POS details at the moment :neutral_face: My deepest apologies, <@%s>", userID))
-		return
+		return newCommandReplyError(fmt.Sprintf("It appears like I can't update the POS details at the moment :neutral_face: My deepest apologies, <@%s>", userID), err)
 	}
 
-	monitored, err := b.getMonitoredStarbaseIDs()
+	poses, total, err := b.getFilteredPOSes(filter)
 	if err != nil {
-		log.WithField("userID", userID).WithError(err).Warn("Failed to retrieve monitored starbase IDs for Discord command")
-		b.recordCommandError("fuel")
-		b.discord.ChannelMessageSend(channelID, fmt.Sprintf("It appears like I can't retrieve a list of monitored POSes at the moment :neutral_face: My deepest apologies, <@%s>", userID))
-		return
+		log.WithField("userID", userID).WithError(err).Warn("Failed to retrieve POSes for Discord command")
+		return newCommandReplyError(fmt.Sprintf("It appears like I can't retrieve a list of monitored POSes at the moment :neutral_face: My deepest apologies, <@%s>", userID), err)
 	}
 
-	b.discord.ChannelMessageSend(channelID, fmt.Sprintf("<@%s> is currently monitoring **%d** POSes.", b.discord.State.User.ID, len(monitored)))
+	if filter.Active() {
+		b.discord.ChannelMessageSend(channelID, fmt.Sprintf("**%d** of %d POSes match your filter.", len(poses), total))
+	} else {
+		b.discord.ChannelMessageSend(channelID, fmt.Sprintf("<@%s> is currently monitoring **%d** POSes.", b.discord.State.User.ID, len(poses)))
+	}
 	b.discord.ChannelTyping(channelID)
 
-	for i, id := range monitored {
-		log.WithField("starbaseID", id).Debug("Checking POS fuel status for Discord command")
-
-		pos, err := b.getPOSFromStarbaseID(id)
-		if err != nil {
-			log.WithFields(logrus.Fields{
-				"userID":     userID,
-				"starbaseID": id,
-			}).WithError(err).Warn("Failed to get POS for Discord command")
+	embeds := make([]*discordgo.MessageEmbed, 0)
+	header := fmt.Sprintf("%-*s %-6s %-12s %-10s", DiscordTableLocationWidth, "Location", "Size", "State", "Remaining")
+	lines := make([]string, 0)
+	totalCost := &FuelCost{}
+	costComplete := true
+	for i, pos := range poses {
+		if compact {
+			hours := pos.HoursRemaining()
+			threshold := b.getFuelThreshold(pos)
+			remaining := formatHoursRemaining(hours)
+			if int(hours) <= threshold.Critical {
+				remaining = fmt.Sprintf("%s !!", remaining)
+			} else if int(hours) <= threshold.Warning {
+				remaining = fmt.Sprintf("%s !", remaining)
+			}
+			lines = append(lines, fmt.Sprintf("%-*s %-6s %-12s %-10s", DiscordTableLocationWidth, truncateString(pos.LocationName, DiscordTableLocationWidth), pos.Size, pos.State, remaining))
+			if b.config.EVE.Pricing.Enabled {
+				if cost, err := b.getPOSFuelCost(pos); err == nil {
+					totalCost.Add(cost)
+				} else {
+					costComplete = false
+				}
+			}
 			continue
 		}
 
-		fields := make([]*discordgo.MessageEmbedField, 0)
-		fields = append(fields, &discordgo.MessageEmbedField{
-			Name:   "Location",
-			Value:  strings.Replace(pos.LocationName, "Moon", ":full_moon_with_face:", -1),
-			Inline: true,
-		})
+		embed, cost := b.formatPOSFuelEmbedForDiscord(pos, userID, fmt.Sprintf(":stars: POS %d/%d", i+1, len(poses)))
+		if cost != nil {
+			totalCost.Add(cost)
+		} else {
+			costComplete = false
+		}
 
-		_, strState := formatStarbaseStateForDiscord(pos.State)
-		fields = append(fields, &discordgo.MessageEmbedField{
-			Name:   "State",
-			Value:  fmt.Sprintf("%s %s", strState, pos.State),
-			Inline: true,
-		})
+		embeds = append(embeds, embed)
+	}
 
-		fields = append(fields, &discordgo.MessageEmbedField{
-			Name:   "Size",
-			Value:  pos.Size.String(),
-			Inline: true,
-		})
+	if len(poses) == 0 {
+		return nil
+	}
 
-		fuelStatus := 0
-		for _, fuel := range pos.Fuel {
-			remaining, err := durafmt.ParseString(fmt.Sprintf("%fh", fuel.HoursRemaining))
-			if err != nil {
-				log.WithFields(logrus.Fields{
-					"userID":     userID,
-					"starbaseID": pos.ID,
-					"fuelTypeID": fuel.TypeID,
-				}).WithError(err).Warn("Failed to parse remaining fuel duration")
-				continue
-			}
-			constantly := "no"
-			if fuel.ConstantlyRequired {
-				constantly = "yes"
-			}
+	if compact {
+		b.sendDiscordTable(channelID, header, lines)
+	} else if err = b.sendDiscordPaginatedEmbeds(channelID, embeds); err != nil {
+		log.WithField("userID", userID).WithError(err).Warn("Failed to send paginated fuel overview")
+		return err
+	}
 
-			remain := remaining.Short()
-			if fuel.ConstantlyRequired {
-				if int(fuel.HoursRemaining) <= b.config.EVE.FuelThreshold.Critical {
-					remain = fmt.Sprintf("__**%s**__", remain)
-					fuelStatus = 2
-				} else if int(fuel.HoursRemaining) <= b.config.EVE.FuelThreshold.Warning {
-					remain = fmt.Sprintf("**%s**", remain)
-					if fuelStatus < 1 {
-						fuelStatus = 1
-					}
-				}
-			}
+	if b.config.EVE.Pricing.Enabled {
+		if costComplete {
+			b.discord.ChannelMessageSend(channelID, fmt.Sprintf("Keeping these POSes fuelled costs an estimated **%s** :moneybag:", totalCost))
+		} else {
+			b.discord.ChannelMessageSend(channelID, fmt.Sprintf("Keeping these POSes fuelled costs at least **%s** :moneybag: Some prices couldn't be retrieved, so this estimate is incomplete.", totalCost))
+		}
+	}
+
+	b.discord.ChannelMessageSend(channelID, fmt.Sprintf("I will shout at you if a POS should fall under %dh fuel remaining (warning, *orange*) and absolutely flip out at %dh fuel left (critical, *red*) :hugging: Some POSes might have their own thresholds, as listed above.", b.config.EVE.FuelThreshold.Warning, b.config.EVE.FuelThreshold.Critical))
+	return nil
+}
+
+func (b *Bot) formatPOSFuelEmbedForDiscord(pos *POS, userID string, title string) (*discordgo.MessageEmbed, *FuelCost) {
+	fields := make([]*discordgo.MessageEmbedField, 0)
+	fields = append(fields, &discordgo.MessageEmbedField{
+		Name:   "Location",
+		Value:  strings.Replace(pos.LocationName, "Moon", ":full_moon_with_face:", -1),
+		Inline: true,
+	})
+
+	_, strState := formatStarbaseStateForDiscord(pos.State)
+	fields = append(fields, &discordgo.MessageEmbedField{
+		Name:   "State",
+		Value:  fmt.Sprintf("%s %s", strState, pos.State),
+		Inline: true,
+	})
+
+	fields = append(fields, &discordgo.MessageEmbedField{
+		Name:   "Size",
+		Value:  pos.Size.String(),
+		Inline: true,
+	})
+
+	threshold := b.getFuelThreshold(pos)
+	fields = append(fields, &discordgo.MessageEmbedField{
+		Name:   "Thresholds",
+		Value:  fmt.Sprintf("*warning*: %dh, *critical*: %dh (%s)", threshold.Warning, threshold.Critical, threshold.Source),
+		Inline: false,
+	})
+
+	ack, err := b.retrieveStarbaseAcknowledgement(pos.ID)
+	if err != nil && err != redis.ErrNil {
+		log.WithFields(logrus.Fields{
+			"userID":     userID,
+			"starbaseID": pos.ID,
+		}).WithError(err).Warn("Failed to retrieve starbase acknowledgement for Discord command")
+	} else if ack != nil {
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   "Acknowledged",
+			Value:  formatStarbaseAcknowledgementForDiscord(ack),
+			Inline: false,
+		})
+	}
 
+	var cost *FuelCost = nil
+	if b.config.EVE.Pricing.Enabled {
+		cost, err = b.getPOSFuelCost(pos)
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"userID":     userID,
+				"starbaseID": pos.ID,
+			}).WithError(err).Warn("Failed to calculate POS fuel cost for Discord command")
+			fields = append(fields, &discordgo.MessageEmbedField{
+				Name:   "Fuel cost",
+				Value:  "*unavailable*",
+				Inline: false,
+			})
+		} else {
 			fields = append(fields, &discordgo.MessageEmbedField{
-				Name:   fmt.Sprintf("Fuel *%s*", fuel.TypeName),
-				Value:  fmt.Sprintf("*quantity*: %d, *remaining*: %s, *used/h*: %d, *constantly required*: %s", fuel.Quantity, remain, fuel.Required, constantly),
+				Name:   "Fuel cost",
+				Value:  cost.String(),
 				Inline: false,
 			})
 		}
+	}
 
-		color := DiscordEmbedColorGreen
-		if fuelStatus == 1 {
-			color = DiscordEmbedColorOrange
-		} else if fuelStatus == 2 {
-			color = DiscordEmbedColorRed
+	fuelStatus := 0
+	for _, fuel := range pos.Fuel {
+		remaining, err := durafmt.ParseString(fmt.Sprintf("%fh", fuel.HoursRemaining))
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"userID":     userID,
+				"starbaseID": pos.ID,
+				"fuelTypeID": fuel.TypeID,
+			}).WithError(err).Warn("Failed to parse remaining fuel duration")
+			continue
+		}
+		constantly := "no"
+		if fuel.ConstantlyRequired {
+			constantly = "yes"
 		}
 
-		embed := &discordgo.MessageEmbed{
-			Color:       color,
-			Title:       fmt.Sprintf(":stars: POS %d/%d", i+1, len(monitored)),
-			Description: fmt.Sprintf("POS owned by **%s**", pos.OwnerName),
-			Fields:      fields,
-			Footer: &discordgo.MessageEmbedFooter{
-				Text: fmt.Sprintf("POS cached for %v", pos.CachedUntil.Sub(time.Now().UTC())),
-			},
+		remain := remaining.Short()
+		if fuel.ConstantlyRequired {
+			if int(fuel.HoursRemaining) <= threshold.Critical {
+				remain = fmt.Sprintf("__**%s**__", remain)
+				fuelStatus = 2
+			} else if int(fuel.HoursRemaining) <= threshold.Warning {
+				remain = fmt.Sprintf("**%s**", remain)
+				if fuelStatus < 1 {
+					fuelStatus = 1
+				}
+			}
 		}
 
-		b.discord.ChannelMessageSendEmbed(channelID, embed)
-		b.discord.ChannelTyping(channelID)
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   fmt.Sprintf("Fuel *%s*", fuel.TypeName),
+			Value:  fmt.Sprintf("*quantity*: %d, *remaining*: %s, *used/h*: %d, *constantly required*: %s", fuel.Quantity, remain, fuel.Required, constantly),
+			Inline: false,
+		})
+	}
+
+	color := DiscordEmbedColorGreen
+	if fuelStatus == 1 {
+		color = DiscordEmbedColorOrange
+	} else if fuelStatus == 2 {
+		color = DiscordEmbedColorRed
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Color:       color,
+		Title:       title,
+		Description: fmt.Sprintf("POS owned by **%s**", pos.OwnerName),
+		Fields:      fields,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("POS cached for %v", pos.CachedUntil.Sub(time.Now().UTC())),
+		},
 	}
 
-	b.discord.ChannelMessageSend(channelID, fmt.Sprintf("I will shout at you if a POS should fall under %dh fuel remaining (warning, *orange*) and absolutely flip out at %dh fuel left (critical, *red*) :hugging:", b.config.EVE.FuelThreshold.Warning, b.config.EVE.FuelThreshold.Critical))
-	b.recordCommandUsage("fuel")
+	return embed, cost
 }
 
-func (b *Bot) handleDiscordPOSListCommand(channelID string, userID string) {
-	starbases, err := b.retrieveStarbaseList()
+func (b *Bot) handleDiscordPOSListCommand(channelID string, userID string, filter *POSFilter, compact bool) error {
+	poses, total, err := b.getFilteredStarbaseList(filter)
 	if err != nil {
-		log.WithField("userID", userID).WithError(err).Warn("Failed to retrieve starbase list for Discord command")
-		b.recordCommandError("list")
-		b.discord.ChannelMessageSend(channelID, fmt.Sprintf("It appears like I can't retrieve a list of POSes at the moment :neutral_face: My deepest apologies, <@%s>", userID))
-		return
+		log.WithField("userID", userID).WithError(err).Warn("Failed to retrieve POSes for Discord command")
+		return newCommandReplyError(fmt.Sprintf("It appears like I can't retrieve a list of POSes at the moment :neutral_face: My deepest apologies, <@%s>", userID), err)
 	}
 
-	b.discord.ChannelMessageSend(channelID, fmt.Sprintf("There is currently **%d** POSes visible to <@%s>, including both monitored and ignored structures.", len(starbases.Starbases), b.discord.State.User.ID))
+	if filter.Active() {
+		b.discord.ChannelMessageSend(channelID, fmt.Sprintf("**%d** of %d POSes visible to <@%s> match your filter.", len(poses), total, b.discord.State.User.ID))
+	} else {
+		b.discord.ChannelMessageSend(channelID, fmt.Sprintf("There is currently **%d** POSes visible to <@%s>, including both monitored and ignored structures.", len(poses), b.discord.State.User.ID))
+	}
 	b.discord.ChannelTyping(channelID)
 
-	for i, starbase := range starbases.Starbases {
+	embeds := make([]*discordgo.MessageEmbed, 0)
+	header := fmt.Sprintf("%-*s %-12s %-10s %s", DiscordTableLocationWidth, "Location", "State", "Monitored", "POSID")
+	lines := make([]string, 0)
+	for i, pos := range poses {
 		fields := make([]*discordgo.MessageEmbedField, 0)
-
-		location, err := b.getLocationNameFromMoonID(starbase.MoonID)
-		if err != nil {
-			log.WithFields(logrus.Fields{
-				"userID":     userID,
-				"starbaseID": starbase.ID,
-				"locationID": starbase.LocationID,
-			}).WithError(err).Warn("Failed to retrieve location name for starbase list")
-			location = fmt.Sprintf("*unknown location - %d*", starbase.LocationID)
-		}
 		fields = append(fields, &discordgo.MessageEmbedField{
 			Name:   "Location",
-			Value:  strings.Replace(location, "Moon", ":full_moon_with_face:", -1),
+			Value:  strings.Replace(pos.LocationName, "Moon", ":full_moon_with_face:", -1),
 			Inline: true,
 		})
 
-		color, strState := formatStarbaseStateForDiscord(starbase.State)
+		color, strState := formatStarbaseStateForDiscord(pos.State)
 		fields = append(fields, &discordgo.MessageEmbedField{
 			Name:   "State",
-			Value:  fmt.Sprintf("%s %s", strState, starbase.State),
+			Value:  fmt.Sprintf("%s %s", strState, pos.State),
 			Inline: true,
 		})
 
-		monitored := b.isStarbaseMonitored(starbase.ID)
 		strMonitored := ":white_check_mark:"
-		if !monitored {
-			strMonitored = ":x:"
+		monitored := "yes"
+		if b.isStarbaseIgnoredByConfig(pos.ID) {
+			strMonitored = ":x: ignored via config"
+			monitored = "no"
+		} else {
+			ignore, err := b.retrieveStarbaseIgnore(pos.ID)
+			if err != nil && err != redis.ErrNil {
+				log.WithFields(logrus.Fields{
+					"userID":     userID,
+					"starbaseID": pos.ID,
+				}).WithError(err).Warn("Failed to retrieve starbase ignore for starbase list")
+			} else if ignore != nil {
+				strMonitored = formatStarbaseIgnoreForDiscord(ignore)
+				monitored = "no"
+			}
+		}
+
+		if compact {
+			lines = append(lines, fmt.Sprintf("%-*s %-12s %-10s %d", DiscordTableLocationWidth, truncateString(pos.LocationName, DiscordTableLocationWidth), pos.State, monitored, pos.ID))
+			continue
 		}
 		fields = append(fields, &discordgo.MessageEmbedField{
 			Name:   "Monitored",
@@ -410,87 +536,737 @@ func (b *Bot) handleDiscordPOSListCommand(channelID string, userID string) {
 			Inline: true,
 		})
 
-		corporationName, err := b.getCorporationNameFromID(starbase.StandingOwnerID)
-		if err != nil {
-			log.WithFields(logrus.Fields{
-				"userID":        userID,
-				"starbaseID":    starbase.ID,
-				"corporationID": starbase.StandingOwnerID,
-			}).WithError(err).Warn("Failed to get corporation name from ID")
-			corporationName = fmt.Sprintf("*unknown corporation - %d*", starbase.StandingOwnerID)
-		}
-
 		embed := &discordgo.MessageEmbed{
 			Color:       color,
-			Title:       fmt.Sprintf(":stars: POS %d/%d", i+1, len(starbases.Starbases)),
-			Description: fmt.Sprintf("POS owned by **%s**", corporationName),
+			Title:       fmt.Sprintf(":stars: POS %d/%d", i+1, len(poses)),
+			Description: fmt.Sprintf("POS owned by **%s**", pos.OwnerName),
 			Fields:      fields,
 			Footer: &discordgo.MessageEmbedFooter{
-				Text: fmt.Sprintf("POS overview cached for %v", starbases.CachedUntil.Time.Sub(time.Now().UTC())),
+				Text: fmt.Sprintf("POS cached for %v", pos.CachedUntil.Sub(time.Now().UTC())),
 			},
 		}
 
-		b.discord.ChannelMessageSendEmbed(channelID, embed)
-		b.discord.ChannelTyping(channelID)
+		embeds = append(embeds, embed)
 	}
 
-	b.discord.ChannelMessageSend(channelID, "You can request additional information about a POS - like it's current fuel status - using `!pos details POSID`.")
-	b.recordCommandUsage("list")
-}
+	if len(poses) == 0 {
+		return nil
+	}
 
-func (b *Bot) handleDiscordPOSRestartCommand(channelID string, userID string) {
-	b.discord.ChannelMessageSend(channelID, "Not implemented yet :innocent:")
-	b.recordCommandError("restart")
-}
+	if compact {
+		b.sendDiscordTable(channelID, header, lines)
+	} else if err = b.sendDiscordPaginatedEmbeds(channelID, embeds); err != nil {
+		log.WithField("userID", userID).WithError(err).Warn("Failed to send paginated starbase list")
+		return err
+	}
 
-func (b *Bot) handleDiscordPOSShutdownCommand(channelID string, userID string) {
-	b.discord.ChannelMessageSend(channelID, "Not implemented yet :innocent:")
-	b.recordCommandError("shutdown")
+	b.discord.ChannelMessageSend(channelID, "You can request additional information about a POS - like it's current fuel status - using `!pos details POSID`.")
+	return nil
 }
 
-func (b *Bot) handleDiscordPOSStatsCommand(channelID string, userID string) {
-	fields := make([]*discordgo.MessageEmbedField, 0)
-
-	log.WithField("userID", userID).Debug("Gathering host info for Discord command")
-	hostInfo, err := host.Info()
+func (b *Bot) handleDiscordPOSPlanCommand(channelID string, userID string, days int) error {
+	err := b.updateMonitoredStarbaseDetails()
 	if err != nil {
-		log.WithField("userID", userID).WithError(err).Warn("Failed to get host info")
-	} else {
-		uptime, _ := time.ParseDuration(fmt.Sprintf("%ds", hostInfo.Uptime))
-		fields = append(fields, &discordgo.MessageEmbedField{
-			Name:   "Host",
-			Value:  fmt.Sprintf("**Hostname**: %s, **OS**: %s %s, **uptime**: %v", hostInfo.Hostname, hostInfo.Platform, hostInfo.PlatformFamily, uptime),
-			Inline: false,
-		})
+		log.WithField("userID", userID).WithError(err).Warn("Failed to update monitored starbase details for Discord command")
+		return newCommandReplyError(fmt.Sprintf("It appears like I can't update the POS details at the moment :neutral_face: My deepest apologies, <@%s>", userID), err)
 	}
 
-	log.WithField("userID", userID).Debug("Gathering host CPU stats for Discord command")
-	cpuCount, err := cpu.Counts(false)
+	poses, err := b.getMonitoredPOSes()
 	if err != nil {
-		log.WithField("userID", userID).WithError(err).Warn("Failed to get host CPU count")
-	} else {
-		cpuUsage, err := cpu.Percent(0, false)
-		if err != nil {
-			log.WithField("userID", userID).WithError(err).Warn("Failed to get host CPU usage")
-		} else {
-			fields = append(fields, &discordgo.MessageEmbedField{
-				Name:   "CPU",
-				Value:  fmt.Sprintf("**Number of cores**: %d, **usage**: %.2f", cpuCount, cpuUsage[0]),
-				Inline: false,
-			})
-		}
+		log.WithField("userID", userID).WithError(err).Warn("Failed to retrieve monitored POSes for Discord command")
+		return newCommandReplyError(fmt.Sprintf("It appears like I can't retrieve a list of monitored POSes at the moment :neutral_face: My deepest apologies, <@%s>", userID), err)
 	}
 
-	log.WithField("userID", userID).Debug("Gathering host load average stats for Discord command")
-	loadAvg, err := load.Avg()
-	if err != nil {
-		log.WithField("userID", userID).WithError(err).Warn("Failed to get host load average stats")
-	} else {
-		fields = append(fields, &discordgo.MessageEmbedField{
-			Name:   "Load average",
-			Value:  fmt.Sprintf("**1 min**: %.2f%%, **5 min**: %.2f%%, **15 min**: %.2f%%", loadAvg.Load1, loadAvg.Load5, loadAvg.Load15),
-			Inline: false,
-		})
+	sort.Slice(poses, func(i, j int) bool {
+		if poses[i].SolarSystemName == poses[j].SolarSystemName {
+			return poses[i].LocationName < poses[j].LocationName
+		}
+		return poses[i].SolarSystemName < poses[j].SolarSystemName
+	})
+
+	header := fmt.Sprintf("%-*s %9s %9s %10s", DiscordTableLocationWidth, "Location", "Blocks", "Stront", "Volume m3")
+	lines := make([]string, 0)
+	totalBlocks, totalStrontium := 0, 0
+	totalVolume := 0.0
+	capped := false
+	system := ""
+	for _, pos := range poses {
+		if pos.SolarSystemName != system {
+			system = pos.SolarSystemName
+			lines = append(lines, fmt.Sprintf("# %s (%s)", system, pos.RegionName))
+		}
+
+		blocks, strontium := 0, 0
+		posCapped := false
+		for _, fuel := range pos.Fuel {
+			if fuel.Type == POSFuelTypeFuelBlock && !fuel.ConstantlyRequired {
+				continue
+			}
+
+			missing, c := fuel.Missing(pos.Size, days)
+			posCapped = posCapped || c
+			if fuel.Type == POSFuelTypeStrontium {
+				strontium += missing
+			} else {
+				blocks += missing
+			}
+		}
+
+		volume := float64(blocks)*fuelUnitVolume(POSFuelTypeFuelBlock) + float64(strontium)*fuelUnitVolume(POSFuelTypeStrontium)
+		totalBlocks += blocks
+		totalStrontium += strontium
+		totalVolume += volume
+
+		location := truncateString(pos.LocationName, DiscordTableLocationWidth-2)
+		if posCapped {
+			location = location + " *"
+			capped = true
+		}
+		lines = append(lines, fmt.Sprintf("%-*s %9d %9d %10.0f", DiscordTableLocationWidth, location, blocks, strontium, volume))
+	}
+	lines = append(lines, fmt.Sprintf("%-*s %9d %9d %10.0f", DiscordTableLocationWidth, "Total", totalBlocks, totalStrontium, totalVolume))
+
+	b.discord.ChannelMessageSend(channelID, fmt.Sprintf(":truck: Refuel plan for **%d** monitored POSes to last **%d days**, grouped by system:", len(poses), days))
+	b.sendDiscordTable(channelID, header, lines)
+
+	summary := fmt.Sprintf("<@%s>, you'll have to haul **%s** fuel blocks and **%s** strontium, totalling **%s m³** :package:", userID, humanize.Comma(int64(totalBlocks)), humanize.Comma(int64(totalStrontium)), humanize.Comma(int64(totalVolume)))
+	if capped {
+		summary = fmt.Sprintf("%s\nPOSes marked with `*` can't hold enough fuel blocks for %d days, their amounts are capped at the fuel bay capacity. Strontium is topped up to fill the strontium bay.", summary, days)
+	}
+	b.discord.ChannelMessageSend(channelID, summary)
+	return nil
+}
+
+func (b *Bot) handleDiscordPOSRefuelsCommand(channelID string, userID string, starbaseID int, days int) error {
+	events, err := b.getRefuelEvents(starbaseID, days)
+	if err != nil {
+		log.WithField("userID", userID).WithError(err).Warn("Failed to retrieve refuel events for Discord command")
+		return newCommandReplyError(fmt.Sprintf("It appears like I can't retrieve any refuels at the moment :neutral_face: My deepest apologies, <@%s>", userID), err)
+	}
+
+	if len(events) == 0 {
+		b.discord.ChannelMessageSend(channelID, fmt.Sprintf("I haven't noticed any refuels within the last %d days :thinking:", days))
+		return nil
+	}
+
+	locations := make(map[int]string)
+	header := fmt.Sprintf("%-16s %-*s %-22s %8s", "Detected (UTC)", DiscordTableLocationWidth, "Location", "Fuel", "Amount")
+	lines := make([]string, 0)
+	for i := len(events) - 1; i >= 0; i-- {
+		event := events[i]
+
+		location, ok := locations[event.StarbaseID]
+		if !ok {
+			location = fmt.Sprintf("POS %d", event.StarbaseID)
+			pos, err := b.getPOSFromStarbaseID(event.StarbaseID)
+			if err != nil {
+				log.WithFields(logrus.Fields{
+					"userID":     userID,
+					"starbaseID": event.StarbaseID,
+				}).WithError(err).Debug("Failed to get POS for refuel event")
+			} else {
+				location = pos.LocationName
+			}
+			locations[event.StarbaseID] = location
+		}
+
+		fuelName := event.FuelTypeName
+		if len(fuelName) == 0 {
+			fuelName = fmt.Sprintf("Type %d", event.FuelTypeID)
+		}
+
+		lines = append(lines, fmt.Sprintf("%-16s %-*s %-22s %8s", event.DetectedAt.Format("2006-01-02 15:04"), DiscordTableLocationWidth, truncateString(location, DiscordTableLocationWidth), truncateString(fuelName, 22), humanize.Comma(int64(event.Amount))))
+	}
+
+	b.discord.ChannelMessageSend(channelID, fmt.Sprintf(":fuelpump: **%d** refuels detected within the last %d days, newest first", len(events), days))
+	b.sendDiscordTable(channelID, header, lines)
+	return nil
+}
+
+func (b *Bot) handleDiscordPOSAckCommand(channelID string, userID string, userName string, starbaseID int, duration time.Duration) error {
+	pos, err := b.getPOSFromStarbaseID(starbaseID)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"userID":     userID,
+			"starbaseID": starbaseID,
+		}).WithError(err).Warn("Failed to get POS for Discord command")
+		return newCommandReplyError(fmt.Sprintf("<@%s>: I don't know any POS with ID %d :thinking:", userID, starbaseID), err)
+	}
+
+	stage := b.getStarbaseEscalationStage(pos)
+	if stage == 0 {
+		return newCommandReplyError(fmt.Sprintf("<@%s>: POS at **%s** has plenty of fuel left, there's nothing to acknowledge :sweat_smile:", userID, pos.LocationName), errors.New("Starbase has no active fuel alert"))
+	}
+
+	return b.acknowledgeStarbases(channelID, userID, userName, []int{starbaseID}, stage, duration)
+}
+
+func (b *Bot) acknowledgeStarbases(channelID string, userID string, userName string, starbaseIDs []int, stage int, duration time.Duration) error {
+	now := time.Now().UTC()
+	locationNames := make([]string, 0)
+
+	var ack *StarbaseAcknowledgement
+	for _, starbaseID := range starbaseIDs {
+		ack = &StarbaseAcknowledgement{
+			StarbaseID:     starbaseID,
+			UserID:         userID,
+			UserName:       userName,
+			Stage:          stage,
+			AcknowledgedAt: now,
+			Expires:        now.Add(duration),
+		}
+
+		err := b.storeStarbaseAcknowledgement(ack)
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"userID":     userID,
+				"starbaseID": starbaseID,
+			}).WithError(err).Warn("Failed to store starbase acknowledgement")
+			return newCommandReplyError(":poop: Seems like there was an error processing this command :poop:", err)
+		}
+
+		locationName := fmt.Sprintf("#%d", starbaseID)
+		pos, err := b.getPOSFromStarbaseID(starbaseID)
+		if err == nil {
+			locationName = pos.LocationName
+		}
+		locationNames = append(locationNames, fmt.Sprintf("**%s**", locationName))
+	}
+
+	if ack == nil {
+		return nil
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Color:       DiscordEmbedColorBlue,
+		Title:       fmt.Sprintf("%s Fuel alert acknowledged", DiscordEmojiAcknowledge),
+		Description: fmt.Sprintf("POS at %s is being taken care of, I'll keep quiet unless things get worse.", strings.Join(locationNames, ", ")),
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:   "Acknowledged",
+				Value:  formatStarbaseAcknowledgementForDiscord(ack),
+				Inline: false,
+			},
+		},
+	}
+
+	b.discord.ChannelMessageSendEmbed(channelID, embed)
+	return nil
+}
+
+func (b *Bot) sendDiscordEscalation(alert *FuelAlert) {
+	var content string
+	if alert.StageIndex == len(b.config.Discord.Escalation) {
+		content = fmt.Sprintf(":rotating_light: POS at **%s** (owned by %s) only has __**%s**__ of fuel **%s** left. FIX THIS SHIT NOW :rage:", alert.POS.LocationName, alert.POS.OwnerName, alert.Remaining, alert.Fuel.TypeName)
+	} else {
+		content = fmt.Sprintf(":alarm_clock: POS at **%s** (owned by %s) has **%s** of fuel **%s** left, someone should probably check that :thinking:", alert.POS.LocationName, alert.POS.OwnerName, alert.Remaining, alert.Fuel.TypeName)
+	}
+
+	if alert.Acknowledgement != nil {
+		content = fmt.Sprintf("%s\n*Previously acknowledged by <@%s>, but things got worse since.*", content, alert.Acknowledgement.UserID)
+		b.deleteStarbaseAcknowledgement(alert.POS.ID)
+	}
+
+	channelID := alert.Stage.ChannelID
+	if len(channelID) == 0 {
+		channelID = b.config.Discord.ChannelID
+	}
+
+	message := newDiscordAlertMessage([]*FuelAlert{alert})
+	message.ChannelID = channelID
+	message.Mention = alert.Stage.Mention
+	message.Content = content
+
+	if err := b.deliverDiscordAlert(message); err != nil {
+		log.WithFields(logrus.Fields{
+			"starbaseID": alert.POS.ID,
+			"channelID":  channelID,
+			"stage":      alert.Stage.Name,
+		}).WithError(err).Warn("Failed to send escalation message")
+	}
+
+	b.sendDiscordEscalationDirectMessages(alert.Stage, []*FuelAlert{alert})
+}
+
+func (b *Bot) sendDiscordEscalationDigest(stageIndex int, stage *EscalationStage, alerts []*FuelAlert) {
+	channelID := stage.ChannelID
+	if len(channelID) == 0 {
+		channelID = b.config.Discord.ChannelID
+	}
+
+	color := DiscordEmbedColorOrange
+	content := fmt.Sprintf(":alarm_clock: **%d** POS fuel alerts reached stage *%s*, someone should probably check those :thinking:", len(alerts), stage.Name)
+	if stageIndex == len(b.config.Discord.Escalation) {
+		color = DiscordEmbedColorRed
+		content = fmt.Sprintf(":rotating_light: **%d** POS fuel alerts reached stage *%s*. FIX THIS SHIT NOW :rage:", len(alerts), stage.Name)
+	}
+
+	for start := 0; start < len(alerts); start += DiscordEmbedMaxFields {
+		end := start + DiscordEmbedMaxFields
+		if end > len(alerts) {
+			end = len(alerts)
+		}
+
+		fields := make([]*discordgo.MessageEmbedField, 0)
+		for _, alert := range alerts[start:end] {
+			value := fmt.Sprintf("*owned by*: %s, **%s** of fuel **%s** left, *POS ID*: %d", alert.POS.OwnerName, alert.Remaining, alert.Fuel.TypeName, alert.POS.ID)
+			if alert.Acknowledgement != nil {
+				value = fmt.Sprintf("%s\n*Previously acknowledged by <@%s>, but things got worse since.*", value, alert.Acknowledgement.UserID)
+				b.deleteStarbaseAcknowledgement(alert.POS.ID)
+			}
+
+			fields = append(fields, &discordgo.MessageEmbedField{
+				Name:   strings.Replace(alert.POS.LocationName, "Moon", ":full_moon_with_face:", -1),
+				Value:  value,
+				Inline: false,
+			})
+		}
+
+		message := newDiscordAlertMessage(alerts[start:end])
+		message.ChannelID = channelID
+		message.Content = content
+		// a single ping per digest is enough, following chunks are posted right after
+		if start == 0 {
+			message.Mention = stage.Mention
+		}
+		message.Embed = &discordgo.MessageEmbed{
+			Color:  color,
+			Title:  fmt.Sprintf(":fuelpump: Fuel alerts - stage *%s*", stage.Name),
+			Fields: fields,
+			Footer: &discordgo.MessageEmbedFooter{
+				Text: fmt.Sprintf("React with %s to acknowledge all listed POSes", DiscordEmojiAcknowledge),
+			},
+		}
+
+		if err := b.deliverDiscordAlert(message); err != nil {
+			log.WithFields(logrus.Fields{
+				"channelID": channelID,
+				"stage":     stage.Name,
+				"count":     len(fields),
+			}).WithError(err).Warn("Failed to send escalation digest message")
+		}
+	}
+
+	b.sendDiscordEscalationDirectMessages(stage, alerts)
+}
+
+func (b *Bot) sendDiscordEscalationDirectMessages(stage *EscalationStage, alerts []*FuelAlert) {
+	if len(stage.DirectMessageUserIDs) == 0 {
+		return
+	}
+
+	lines := make([]string, 0)
+	for _, alert := range alerts {
+		lines = append(lines, fmt.Sprintf(":fuelpump: POS at **%s** (owned by %s) has **%s** of fuel **%s** left (stage *%s*)", alert.POS.LocationName, alert.POS.OwnerName, alert.Remaining, alert.Fuel.TypeName, stage.Name))
+	}
+	content := fmt.Sprintf("%s\nPlease take care of it :pray:", strings.Join(lines, "\n"))
+
+	for _, userID := range stage.DirectMessageUserIDs {
+		message := newDiscordAlertMessage(alerts)
+		message.UserID = userID
+		message.Content = content
+
+		if err := b.deliverDiscordAlert(message); err != nil {
+			log.WithFields(logrus.Fields{
+				"userID": userID,
+				"stage":  stage.Name,
+			}).WithError(err).Warn("Failed to send escalation direct message")
+		}
+	}
+}
+
+func (b *Bot) sendDiscordSubscriptionDirectMessages(alerts []*FuelAlert) {
+	subscriptions, err := b.retrieveAllSubscriptions()
+	if err != nil {
+		log.WithError(err).Warn("Failed to retrieve subscriptions for fuel alerts")
+		return
+	}
+
+	for userID, userSubscriptions := range subscriptions {
+		lines := make([]string, 0)
+		matched := make([]*FuelAlert, 0)
+		for _, alert := range alerts {
+			if alert.Stage != nil && alert.Stage.hasDirectMessageUser(userID) {
+				continue
+			}
+
+			for _, subscription := range userSubscriptions {
+				if b.subscriptionCovers(subscription, alert) {
+					lines = append(lines, fmt.Sprintf(":fuelpump: POS at **%s** (owned by %s) has **%s** of fuel **%s** left (subscribed via %s)", alert.POS.LocationName, alert.POS.OwnerName, alert.Remaining, alert.Fuel.TypeName, subscription))
+					matched = append(matched, alert)
+					break
+				}
+			}
+		}
+
+		if len(lines) == 0 {
+			continue
+		}
+
+		message := newDiscordAlertMessage(matched)
+		message.UserID = userID
+		message.Content = fmt.Sprintf("%s\nYou can manage your subscriptions using `!pos subscriptions` :bell:", strings.Join(lines, "\n"))
+
+		if err = b.deliverDiscordAlert(message); err != nil {
+			log.WithFields(logrus.Fields{
+				"userID": userID,
+				"count":  len(lines),
+			}).WithError(err).Warn("Failed to send subscription direct message")
+			continue
+		}
+
+		log.WithFields(logrus.Fields{
+			"userID": userID,
+			"count":  len(lines),
+		}).Info("Subscription direct message sent")
+	}
+}
+
+func (b *Bot) handleDiscordPOSSubscribeCommand(channelID string, userID string, target string, severity string) error {
+	subscription, err := parseSubscription(target, severity)
+	if err != nil {
+		return newCommandReplyError(fmt.Sprintf("<@%s>: I can't make sense of `%s` :thinking: Try a POS ID, `corp NAME` or `region NAME`.", userID, target), err)
+	}
+
+	if subscription.Type == SubscriptionTypeStarbase {
+		starbases, err := b.retrieveStarbaseList()
+		if err != nil {
+			log.WithField("userID", userID).WithError(err).Warn("Failed to retrieve starbase list for Discord command")
+			return newCommandReplyError(fmt.Sprintf("It appears like I can't retrieve a list of POSes at the moment :neutral_face: My deepest apologies, <@%s>", userID), err)
+		}
+
+		starbaseID, _ := strconv.Atoi(subscription.Target)
+		found := false
+		for _, starbase := range starbases.Starbases {
+			if starbase.ID == starbaseID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return newCommandReplyError(fmt.Sprintf("<@%s>: I don't know any POS with ID %d :thinking:", userID, starbaseID), errors.New("Starbase not found"))
+		}
+	}
+
+	subscriptions, err := b.retrieveSubscriptions(userID)
+	if err != nil {
+		log.WithField("userID", userID).WithError(err).Warn("Failed to retrieve subscriptions for Discord command")
+		return newCommandReplyError(fmt.Sprintf("It appears like I can't retrieve your subscriptions at the moment :neutral_face: My deepest apologies, <@%s>", userID), err)
+	}
+
+	updated := false
+	for i, existing := range subscriptions {
+		if existing.Key() == subscription.Key() {
+			subscriptions[i] = subscription
+			updated = true
+			break
+		}
+	}
+	if !updated {
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	if err = b.storeSubscriptions(userID, subscriptions); err != nil {
+		log.WithField("userID", userID).WithError(err).Warn("Failed to store subscriptions for Discord command")
+		return newCommandReplyError(fmt.Sprintf("It appears like I can't save your subscription at the moment :neutral_face: My deepest apologies, <@%s>", userID), err)
+	}
+
+	log.WithFields(logrus.Fields{
+		"userID":   userID,
+		"type":     subscription.Type,
+		"target":   subscription.Target,
+		"severity": subscription.Severity,
+	}).Info("Stored subscription")
+	b.discord.ChannelMessageSend(channelID, fmt.Sprintf("<@%s>: Alright, I'll send you a direct message for *%s* alerts of %s :bell:", userID, subscription.Severity, subscription))
+	return nil
+}
+
+func (b *Bot) handleDiscordPOSUnsubscribeCommand(channelID string, userID string, target string) error {
+	subscriptions, err := b.retrieveSubscriptions(userID)
+	if err != nil {
+		log.WithField("userID", userID).WithError(err).Warn("Failed to retrieve subscriptions for Discord command")
+		return newCommandReplyError(fmt.Sprintf("It appears like I can't retrieve your subscriptions at the moment :neutral_face: My deepest apologies, <@%s>", userID), err)
+	}
+
+	remaining := make([]*Subscription, 0)
+	if len(target) > 0 {
+		subscription, err := parseSubscription(target, "")
+		if err != nil {
+			return newCommandReplyError(fmt.Sprintf("<@%s>: I can't make sense of `%s` :thinking: Try a POS ID, `corp NAME` or `region NAME`.", userID, target), err)
+		}
+
+		for _, existing := range subscriptions {
+			if existing.Key() != subscription.Key() {
+				remaining = append(remaining, existing)
+			}
+		}
+	}
+
+	if len(remaining) == len(subscriptions) {
+		b.discord.ChannelMessageSend(channelID, fmt.Sprintf("<@%s>: You're not subscribed to that :thinking:", userID))
+		return nil
+	}
+
+	if err = b.storeSubscriptions(userID, remaining); err != nil {
+		log.WithField("userID", userID).WithError(err).Warn("Failed to store subscriptions for Discord command")
+		return newCommandReplyError(fmt.Sprintf("It appears like I can't update your subscriptions at the moment :neutral_face: My deepest apologies, <@%s>", userID), err)
+	}
+
+	log.WithFields(logrus.Fields{
+		"userID":  userID,
+		"removed": len(subscriptions) - len(remaining),
+	}).Info("Removed subscriptions")
+	b.discord.ChannelMessageSend(channelID, fmt.Sprintf("<@%s>: Removed %d subscription(s), you've got %d left :no_bell:", userID, len(subscriptions)-len(remaining), len(remaining)))
+	return nil
+}
+
+func (b *Bot) handleDiscordPOSSubscriptionsCommand(channelID string, userID string) error {
+	subscriptions, err := b.retrieveSubscriptions(userID)
+	if err != nil {
+		log.WithField("userID", userID).WithError(err).Warn("Failed to retrieve subscriptions for Discord command")
+		return newCommandReplyError(fmt.Sprintf("It appears like I can't retrieve your subscriptions at the moment :neutral_face: My deepest apologies, <@%s>", userID), err)
+	}
+
+	if len(subscriptions) == 0 {
+		b.discord.ChannelMessageSend(channelID, fmt.Sprintf("<@%s>: You're not subscribed to any POS alerts. Use `!pos subscribe POSID` to get direct messages for a POS :bell:", userID))
+		return nil
+	}
+
+	lines := []string{fmt.Sprintf("<@%s>: You're receiving direct messages for **%d** subscription(s):", userID, len(subscriptions))}
+	for _, subscription := range subscriptions {
+		lines = append(lines, fmt.Sprintf(":bell: %s, *%s* alerts, since %s", subscription, subscription.Severity, subscription.Created.Format("2006-01-02")))
+	}
+
+	b.sendDiscordLines(channelID, lines)
+	return nil
+}
+
+func (b *Bot) handleDiscordPOSIgnoreCommand(channelID string, userID string, userName string, starbaseID int, reason string, duration time.Duration) error {
+	if b.isStarbaseIgnoredByConfig(starbaseID) {
+		return newCommandReplyError(fmt.Sprintf("<@%s>: POS %d is already being ignored via my config file :face_palm:", userID, starbaseID), errors.New("Starbase already ignored via config"))
+	}
+
+	starbases, err := b.retrieveStarbaseList()
+	if err != nil {
+		log.WithField("userID", userID).WithError(err).Warn("Failed to retrieve starbase list for Discord command")
+		return newCommandReplyError(fmt.Sprintf("It appears like I can't retrieve a list of POSes at the moment :neutral_face: My deepest apologies, <@%s>", userID), err)
+	}
+
+	found := false
+	for _, starbase := range starbases.Starbases {
+		if starbase.ID == starbaseID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return newCommandReplyError(fmt.Sprintf("<@%s>: I don't know any POS with ID %d :thinking:", userID, starbaseID), errors.New("Starbase not found"))
+	}
+
+	ignore := &StarbaseIgnore{
+		StarbaseID: starbaseID,
+		UserID:     userID,
+		UserName:   userName,
+		Reason:     reason,
+		IgnoredAt:  time.Now().UTC(),
+	}
+	if duration > 0 {
+		ignore.Expires = ignore.IgnoredAt.Add(duration)
+	}
+
+	err = b.storeStarbaseIgnore(ignore)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"userID":     userID,
+			"starbaseID": starbaseID,
+		}).WithError(err).Warn("Failed to store starbase ignore for Discord command")
+		return newCommandReplyError(":poop: Seems like there was an error processing this command :poop:", err)
+	}
+
+	b.discord.ChannelMessageSend(channelID, fmt.Sprintf("Alright <@%s>, I'll stop monitoring POS %d: %s", userID, starbaseID, formatStarbaseIgnoreForDiscord(ignore)))
+	return nil
+}
+
+func (b *Bot) handleDiscordPOSUnignoreCommand(channelID string, userID string, starbaseID int) error {
+	if b.isStarbaseIgnoredByConfig(starbaseID) {
+		return newCommandReplyError(fmt.Sprintf("<@%s>: POS %d is ignored via my config file, you'll have to remove it there :face_palm:", userID, starbaseID), errors.New("Starbase ignored via config"))
+	}
+
+	err := b.deleteStarbaseIgnore(starbaseID)
+	if err == redis.ErrNil {
+		return newCommandReplyError(fmt.Sprintf("<@%s>: I wasn't ignoring POS %d in the first place :thinking:", userID, starbaseID), errors.New("Starbase not ignored"))
+	} else if err != nil {
+		log.WithFields(logrus.Fields{
+			"userID":     userID,
+			"starbaseID": starbaseID,
+		}).WithError(err).Warn("Failed to delete starbase ignore for Discord command")
+		return newCommandReplyError(":poop: Seems like there was an error processing this command :poop:", err)
+	}
+
+	b.discord.ChannelMessageSend(channelID, fmt.Sprintf("Got it <@%s>, I'll keep an eye on POS %d again :eyes:", userID, starbaseID))
+	return nil
+}
+
+func (b *Bot) handleDiscordPOSThresholdCommand(channelID string, userID string, starbaseID int, threshold *FuelThreshold) error {
+	pos, err := b.getPOSFromStarbaseID(starbaseID)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"userID":     userID,
+			"starbaseID": starbaseID,
+		}).WithError(err).Warn("Failed to get POS for Discord command")
+		return newCommandReplyError(fmt.Sprintf("<@%s>: I don't know any POS with ID %d :thinking:", userID, starbaseID), err)
+	}
+
+	previous, err := b.retrieveStarbaseThreshold(starbaseID)
+	if err != nil && err != redis.ErrNil {
+		log.WithFields(logrus.Fields{
+			"userID":     userID,
+			"starbaseID": starbaseID,
+		}).WithError(err).Warn("Failed to retrieve starbase threshold for Discord command")
+	} else if previous != nil {
+		if threshold.Warning <= 0 {
+			threshold.Warning = previous.Warning
+		}
+		if threshold.Critical <= 0 {
+			threshold.Critical = previous.Critical
+		}
+	}
+
+	effective := b.getFuelThreshold(pos)
+	warning, critical := threshold.Warning, threshold.Critical
+	if warning <= 0 {
+		warning = effective.Warning
+	}
+	if critical <= 0 {
+		critical = effective.Critical
+	}
+	if critical >= warning {
+		return newCommandReplyError(fmt.Sprintf("<@%s>: The critical threshold (%dh) has to be lower than the warning one (%dh) :face_palm:", userID, critical, warning), errors.New("Critical threshold higher than warning threshold"))
+	}
+	if err = validateEscalationStages(b.config.Discord.Escalation, FuelThreshold{Warning: warning, Critical: critical}); err != nil {
+		return newCommandReplyError(fmt.Sprintf("<@%s>: These thresholds would mess up the order of the configured escalation stages :face_palm: %s", userID, err), err)
+	}
+
+	err = b.storeStarbaseThreshold(starbaseID, threshold)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"userID":     userID,
+			"starbaseID": starbaseID,
+		}).WithError(err).Warn("Failed to store starbase threshold for Discord command")
+		return newCommandReplyError(":poop: Seems like there was an error processing this command :poop:", err)
+	}
+
+	b.discord.ChannelMessageSend(channelID, fmt.Sprintf("Alright <@%s>, POS at **%s** will now have a warning threshold of %dh and a critical one of %dh :ok_hand:", userID, pos.LocationName, warning, critical))
+	return nil
+}
+
+func (b *Bot) handleDiscordPOSThresholdResetCommand(channelID string, userID string, starbaseID int) error {
+	err := b.deleteStarbaseThreshold(starbaseID)
+	if err == redis.ErrNil {
+		return newCommandReplyError(fmt.Sprintf("<@%s>: POS %d didn't have any thresholds set in the first place :thinking:", userID, starbaseID), errors.New("Starbase has no threshold override"))
+	} else if err != nil {
+		log.WithFields(logrus.Fields{
+			"userID":     userID,
+			"starbaseID": starbaseID,
+		}).WithError(err).Warn("Failed to delete starbase threshold for Discord command")
+		return newCommandReplyError(":poop: Seems like there was an error processing this command :poop:", err)
+	}
+
+	b.discord.ChannelMessageSend(channelID, fmt.Sprintf("Got it <@%s>, POS %d will use the configured thresholds again :ok_hand:", userID, starbaseID))
+	return nil
+}
+
+func (b *Bot) handleDiscordPOSRestartCommand(channelID string, userID string) error {
+	return newCommandReplyError("Not implemented yet :innocent:", errors.New("Not implemented"))
+}
+
+func (b *Bot) handleDiscordPOSShutdownCommand(channelID string, userID string) error {
+	return newCommandReplyError("Not implemented yet :innocent:", errors.New("Not implemented"))
+}
+
+func (b *Bot) handleDiscordPOSDeadLettersCommand(channelID string, userID string, clear bool) error {
+	if b.webhook == nil {
+		b.discord.ChannelMessageSend(channelID, fmt.Sprintf("<@%s>: Webhooks aren't enabled, so there's nothing that could have failed :shrug:", userID))
+		return nil
+	}
+
+	if clear {
+		if err := b.deleteWebhookDeadLetters(); err != nil {
+			log.WithField("userID", userID).WithError(err).Warn("Failed to delete webhook dead letters for Discord command")
+			return newCommandReplyError(fmt.Sprintf("It appears like I can't clear the failed webhook events at the moment :neutral_face: My deepest apologies, <@%s>", userID), err)
+		}
+
+		log.WithField("userID", userID).Info("Cleared webhook dead letters")
+		b.discord.ChannelMessageSend(channelID, fmt.Sprintf("<@%s>: Cleared all failed webhook events :wastebasket:", userID))
+		return nil
+	}
+
+	letters, total, err := b.retrieveWebhookDeadLetters(WebhookDeadLetterListDefault)
+	if err != nil {
+		log.WithField("userID", userID).WithError(err).Warn("Failed to retrieve webhook dead letters for Discord command")
+		return newCommandReplyError(fmt.Sprintf("It appears like I can't retrieve the failed webhook events at the moment :neutral_face: My deepest apologies, <@%s>", userID), err)
+	}
+
+	if total == 0 {
+		b.discord.ChannelMessageSend(channelID, fmt.Sprintf("<@%s>: All webhook events were delivered successfully :tada:", userID))
+		return nil
+	}
+
+	b.discord.ChannelMessageSend(channelID, fmt.Sprintf("<@%s>: **%d** webhook event(s) could not be delivered, showing the latest %d:", userID, total, len(letters)))
+
+	header := fmt.Sprintf("%-16s %-22s %-8s %s", "Failed", "Event", "Attempts", "URL / Error")
+	lines := make([]string, 0)
+	for _, letter := range letters {
+		lines = append(lines, fmt.Sprintf("%-16s %-22s %-8d %s\n    %s", letter.FailedAt.Format("2006-01-02 15:04"), letter.EventType, letter.Attempts, letter.URL, truncateString(letter.Error, 120)))
+	}
+
+	b.sendDiscordTable(channelID, header, lines)
+	return nil
+}
+
+func (b *Bot) handleDiscordPOSStatsCommand(channelID string, userID string) error {
+	fields := make([]*discordgo.MessageEmbedField, 0)
+
+	log.WithField("userID", userID).Debug("Gathering host info for Discord command")
+	hostInfo, err := host.Info()
+	if err != nil {
+		log.WithField("userID", userID).WithError(err).Warn("Failed to get host info")
+	} else {
+		uptime, _ := time.ParseDuration(fmt.Sprintf("%ds", hostInfo.Uptime))
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   "Host",
+			Value:  fmt.Sprintf("**Hostname**: %s, **OS**: %s %s, **uptime**: %v", hostInfo.Hostname, hostInfo.Platform, hostInfo.PlatformFamily, uptime),
+			Inline: false,
+		})
+	}
+
+	log.WithField("userID", userID).Debug("Gathering host CPU stats for Discord command")
+	cpuCount, err := cpu.Counts(false)
+	if err != nil {
+		log.WithField("userID", userID).WithError(err).Warn("Failed to get host CPU count")
+	} else {
+		cpuUsage, err := cpu.Percent(0, false)
+		if err != nil {
+			log.WithField("userID", userID).WithError(err).Warn("Failed to get host CPU usage")
+		} else {
+			fields = append(fields, &discordgo.MessageEmbedField{
+				Name:   "CPU",
+				Value:  fmt.Sprintf("**Number of cores**: %d, **usage**: %.2f", cpuCount, cpuUsage[0]),
+				Inline: false,
+			})
+		}
+	}
+
+	log.WithField("userID", userID).Debug("Gathering host load average stats for Discord command")
+	loadAvg, err := load.Avg()
+	if err != nil {
+		log.WithField("userID", userID).WithError(err).Warn("Failed to get host load average stats")
+	} else {
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   "Load average",
+			Value:  fmt.Sprintf("**1 min**: %.2f%%, **5 min**: %.2f%%, **15 min**: %.2f%%", loadAvg.Load1, loadAvg.Load5, loadAvg.Load15),
+			Inline: false,
+		})
 	}
 
 	log.WithField("userID", userID).Debug("Gathering host memory stats for Discord command")
@@ -514,9 +1290,7 @@ func (b *Bot) handleDiscordPOSStatsCommand(channelID string, userID string) {
 	stats, err := b.retrieveCommandStats()
 	if err != nil {
 		log.WithField("userID", userID).WithError(err).Warn("Failed to retrieve command stats")
-		b.recordCommandError("stats")
-		b.discord.ChannelMessageSend(channelID, ":poop: Seems like there was an error processing this command :poop:")
-		return
+		return newCommandReplyError(":poop: Seems like there was an error processing this command :poop:", err)
 	}
 
 	for command, stat := range stats {
@@ -546,7 +1320,186 @@ func (b *Bot) handleDiscordPOSStatsCommand(channelID string, userID string) {
 	}
 
 	b.discord.ChannelMessageSendEmbed(channelID, embed)
-	b.recordCommandUsage("stats")
+	return nil
+}
+
+func (b *Bot) sendDiscordFuelReport(report *FuelReport) error {
+	channelID := b.getReportChannelID()
+
+	header := fmt.Sprintf("%-*s %-6s %-10s %8s", DiscordTableLocationWidth, "Location", "Size", "Remaining", "Blocks")
+	if report.Pricing {
+		header = fmt.Sprintf("%s %14s", header, "ISK/day")
+	}
+	lines := make([]string, 0)
+	for _, entry := range report.Entries {
+		line := fmt.Sprintf("%-*s %-6s %-10s %8d", DiscordTableLocationWidth, truncateString(entry.POS.LocationName, DiscordTableLocationWidth), entry.POS.Size, formatHoursRemaining(entry.POS.HoursRemaining()), entry.Blocks)
+		if report.Pricing {
+			if entry.Cost == nil {
+				line = fmt.Sprintf("%s %14s", line, "n/a")
+			} else {
+				line = fmt.Sprintf("%s %14s", line, formatISK(entry.Cost.Daily))
+			}
+		}
+		lines = append(lines, line)
+	}
+
+	_, err := b.discord.ChannelMessageSend(channelID, fmt.Sprintf(":clipboard: **POS fuel report** for %s - %d monitored POSes, sorted by remaining fuel", report.GeneratedAt.Format(time.RFC1123), len(report.Entries)))
+	if err != nil {
+		return errors.Wrap(err, "Failed to send fuel report")
+	}
+	b.sendDiscordTable(channelID, header, lines)
+	b.discord.ChannelMessageSend(channelID, fmt.Sprintf("Topping up every POS to %d days of fuel requires **%s** fuel blocks in total :fuelpump:", report.Days, humanize.Comma(int64(report.TotalBlocks))))
+	if report.Pricing {
+		b.discord.ChannelMessageSend(channelID, fmt.Sprintf("Estimated fuel cost for all monitored POSes: **%s** :moneybag:", report.TotalCost))
+	}
+
+	return nil
+}
+
+func (b *Bot) sendDiscordLines(channelID string, lines []string) {
+	message := ""
+	for _, line := range lines {
+		if len(message) > 0 && len(message)+len(line)+1 >= DiscordMessageMaxLength {
+			b.discord.ChannelMessageSend(channelID, message)
+			message = ""
+		}
+		if len(message) > 0 {
+			message += "\n"
+		}
+		message += line
+	}
+
+	if len(message) > 0 {
+		b.discord.ChannelMessageSend(channelID, message)
+	}
+}
+
+func (b *Bot) sendDiscordTable(channelID string, header string, lines []string) {
+	block := header
+	for _, line := range lines {
+		if len(block)+len(line)+len("```\n\n```") >= DiscordMessageMaxLength {
+			b.discord.ChannelMessageSend(channelID, fmt.Sprintf("```\n%s```", block))
+			block = header
+		}
+		block = fmt.Sprintf("%s\n%s", block, line)
+	}
+
+	b.discord.ChannelMessageSend(channelID, fmt.Sprintf("```\n%s```", block))
+}
+
+func (b *Bot) notifyDiscordStarbaseAdded(starbase *eveapi.Starbase) {
+	embed := b.formatStarbaseChangeEmbedForDiscord(starbase)
+	embed.Color = DiscordEmbedColorBlue
+	embed.Title = ":new: New POS detected"
+
+	b.discord.ChannelMessageSendEmbed(b.config.Discord.ChannelID, embed)
+}
+
+func (b *Bot) notifyDiscordStarbaseRemoved(starbase *eveapi.Starbase) {
+	embed := b.formatStarbaseChangeEmbedForDiscord(starbase)
+	embed.Color = DiscordEmbedColorOrange
+	embed.Title = ":wastebasket: POS removed"
+
+	if starbase.State == eveapi.StarbaseStateOnline || starbase.State == eveapi.StarbaseStateReinforced {
+		embed.Color = DiscordEmbedColorRed
+		embed.Title = ":boom: POS disappeared while online"
+		b.discord.ChannelMessageSend(b.config.Discord.ChannelID, "@everyone :skull_crossbones: A POS just vanished from the starbase list whilst being online - it was most likely killed :sob:")
+	}
+
+	b.discord.ChannelMessageSendEmbed(b.config.Discord.ChannelID, embed)
+}
+
+func (b *Bot) formatStarbaseChangeEmbedForDiscord(starbase *eveapi.Starbase) *discordgo.MessageEmbed {
+	fields := make([]*discordgo.MessageEmbedField, 0)
+
+	location, err := b.getLocationNameFromMoonID(starbase.MoonID)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"starbaseID": starbase.ID,
+			"locationID": starbase.MoonID,
+		}).WithError(err).Warn("Failed to retrieve location name for starbase change")
+		location = fmt.Sprintf("*unknown location - %d*", starbase.MoonID)
+	}
+	fields = append(fields, &discordgo.MessageEmbedField{
+		Name:   "Location",
+		Value:  strings.Replace(location, "Moon", ":full_moon_with_face:", -1),
+		Inline: true,
+	})
+
+	_, strState := formatStarbaseStateForDiscord(starbase.State)
+	fields = append(fields, &discordgo.MessageEmbedField{
+		Name:   "State",
+		Value:  fmt.Sprintf("%s %s", strState, starbase.State),
+		Inline: true,
+	})
+
+	fields = append(fields, &discordgo.MessageEmbedField{
+		Name:   "POS ID",
+		Value:  strconv.Itoa(starbase.ID),
+		Inline: true,
+	})
+
+	corporationName, err := b.getCorporationNameFromID(starbase.StandingOwnerID)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"starbaseID":    starbase.ID,
+			"corporationID": starbase.StandingOwnerID,
+		}).WithError(err).Warn("Failed to get corporation name for starbase change")
+		corporationName = fmt.Sprintf("*unknown corporation - %d*", starbase.StandingOwnerID)
+	}
+
+	return &discordgo.MessageEmbed{
+		Description: fmt.Sprintf("POS owned by **%s**", corporationName),
+		Fields:      fields,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("Change detected at: %s", time.Now().UTC().Format(time.RFC1123)),
+		},
+	}
+}
+
+func formatStarbaseAcknowledgementForDiscord(ack *StarbaseAcknowledgement) string {
+	return fmt.Sprintf("%s by <@%s> until %s", DiscordEmojiAcknowledge, ack.UserID, ack.Expires.Format(time.RFC1123))
+}
+
+func formatStarbaseIgnoreForDiscord(ignore *StarbaseIgnore) string {
+	str := fmt.Sprintf(":x: ignored by <@%s>", ignore.UserID)
+	if len(ignore.Reason) > 0 {
+		str = fmt.Sprintf("%s (*%s*)", str, ignore.Reason)
+	}
+	if !ignore.Expires.IsZero() {
+		str = fmt.Sprintf("%s until %s", str, ignore.Expires.Format(time.RFC1123))
+	}
+	return str
+}
+
+func formatHoursRemaining(hours float64) string {
+	remaining, err := durafmt.ParseString(fmt.Sprintf("%fh", hours))
+	if err != nil {
+		return fmt.Sprintf("%.0fh", hours)
+	}
+
+	return remaining.Short()
+}
+
+func truncateString(str string, length int) string {
+	runes := []rune(str)
+	if len(runes) <= length {
+		return str
+	}
+
+	return string(runes[:length-1]) + "…"
+}
+
+func parseDuration(str string) (time.Duration, error) {
+	if strings.HasSuffix(str, "d") {
+		days, err := strconv.ParseFloat(strings.TrimSuffix(str, "d"), 64)
+		if err != nil {
+			return 0, errors.Wrap(err, "Failed to parse duration in days")
+		}
+		return time.Duration(days * float64(24*time.Hour)), nil
+	}
+
+	return time.ParseDuration(str)
 }
 
 func formatStarbaseStateForDiscord(state eveapi.StarbaseState) (int, string) {
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"github.com/Sirupsen/logrus"
+	"github.com/dustin/go-humanize"
+	"github.com/pkg/errors"
+	"html/template"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+const (
+	EmailSecurityNone     = "none"
+	EmailSecuritySTARTTLS = "starttls"
+	EmailSecurityTLS      = "tls"
+	EmailSessionTimeout   = 60
+)
+
+type EmailRecipient struct {
+	Address  string `json:"address"`
+	Severity string `json:"severity"`
+	Report   bool   `json:"report"`
+}
+
+type EmailNotifier struct {
+	Address    string
+	Username   string
+	Password   string
+	From       string
+	Security   string
+	Recipients []EmailRecipient
+}
+
+var emailAlertTemplate = template.Must(template.New("alerts").Parse(`<html>
+<body style="font-family: sans-serif;">
+<h2>{{len .}} POS fuel alert(s)</h2>
+<table cellpadding="6" style="border-collapse: collapse;">
+<tr style="background: #eeeeee;"><th align="left">Location</th><th align="left">Owner</th><th align="left">Fuel</th><th align="left">Remaining</th><th align="left">Severity</th><th align="left">POS ID</th></tr>
+{{range .}}<tr{{if eq .Severity "critical"}} style="color: #d9534f;"{{end}}><td>{{.POS.LocationName}}</td><td>{{.POS.OwnerName}}</td><td>{{.Fuel.TypeName}}</td><td>{{.Remaining}}</td><td>{{.Severity}}</td><td>{{.POS.ID}}</td></tr>
+{{end}}</table>
+<p>Acknowledge alerts on Discord using <code>!pos ack POSID</code>.</p>
+</body>
+</html>
+`))
+
+var emailReportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"hours": formatHoursRemaining,
+	"isk":   formatISK,
+	"comma": func(i int) string { return humanize.Comma(int64(i)) },
+}).Parse(`<html>
+<body style="font-family: sans-serif;">
+<h2>POS fuel report for {{.GeneratedAt.Format "Mon, 02 Jan 2006 15:04 MST"}}</h2>
+<p>{{len .Entries}} monitored POSes, sorted by remaining fuel.</p>
+<table cellpadding="6" style="border-collapse: collapse;">
+<tr style="background: #eeeeee;"><th align="left">Location</th><th align="left">Size</th><th align="left">Remaining</th><th align="right">Blocks</th>{{if .Pricing}}<th align="right">ISK/day</th>{{end}}</tr>
+{{range .Entries}}<tr><td>{{.POS.LocationName}}</td><td>{{.POS.Size}}</td><td>{{hours .POS.HoursRemaining}}</td><td align="right">{{.Blocks}}</td>{{if $.Pricing}}<td align="right">{{if .Cost}}{{isk .Cost.Daily}}{{else}}n/a{{end}}</td>{{end}}</tr>
+{{end}}</table>
+<p>Topping up every POS to {{.Days}} days of fuel requires <b>{{comma .TotalBlocks}}</b> fuel blocks in total.</p>
+{{if .Pricing}}<p>Estimated fuel cost for all monitored POSes: <b>{{.TotalCost}}</b></p>{{end}}
+</body>
+</html>
+`))
+
+func NewEmailNotifier(address string, username string, password string, from string, security string, recipients []EmailRecipient) *EmailNotifier {
+	return &EmailNotifier{
+		Address:    address,
+		Username:   username,
+		Password:   password,
+		From:       from,
+		Security:   strings.ToLower(security),
+		Recipients: recipients,
+	}
+}
+
+func (n *EmailNotifier) Name() string {
+	return "email"
+}
+
+func (n *EmailNotifier) Notify(alerts []*FuelAlert) error {
+	var lastErr error
+	for _, recipient := range n.Recipients {
+		filtered := filterFuelAlerts(alerts, recipient.Severity)
+		if len(filtered) == 0 {
+			continue
+		}
+
+		lines := make([]string, 0)
+		for _, alert := range filtered {
+			lines = append(lines, fmt.Sprintf("- %s (owned by %s): %s of %s left, %s, POS ID %d", alert.POS.LocationName, alert.POS.OwnerName, alert.Remaining, alert.Fuel.TypeName, alert.Severity, alert.POS.ID))
+		}
+		text := fmt.Sprintf("%d POS fuel alert(s):\n\n%s\n\nAcknowledge alerts on Discord using !pos ack POSID.\n", len(filtered), strings.Join(lines, "\n"))
+
+		var html bytes.Buffer
+		if err := emailAlertTemplate.Execute(&html, filtered); err != nil {
+			return errors.Wrap(err, "Failed to render alert email")
+		}
+
+		subject := fmt.Sprintf("[POSbot] %d POS fuel alert(s)", len(filtered))
+		if err := n.send(recipient.Address, subject, text, html.String()); err != nil {
+			log.WithField("recipient", recipient.Address).WithError(err).Warn("Failed to send alert email")
+			lastErr = err
+			continue
+		}
+
+		recordNotificationsSent(n.Name(), filtered)
+		log.WithFields(logrus.Fields{
+			"recipient": recipient.Address,
+			"count":     len(filtered),
+		}).Info("Alert email sent")
+	}
+
+	return lastErr
+}
+
+func (n *EmailNotifier) SendReport(report *FuelReport) error {
+	lines := make([]string, 0)
+	for _, entry := range report.Entries {
+		line := fmt.Sprintf("- %s (%s): %s remaining, %d blocks", entry.POS.LocationName, entry.POS.Size, formatHoursRemaining(entry.POS.HoursRemaining()), entry.Blocks)
+		if report.Pricing && entry.Cost != nil {
+			line = fmt.Sprintf("%s, %s ISK/day", line, formatISK(entry.Cost.Daily))
+		}
+		lines = append(lines, line)
+	}
+	text := fmt.Sprintf("POS fuel report for %s - %d monitored POSes, sorted by remaining fuel:\n\n%s\n\nTopping up every POS to %d days of fuel requires %s fuel blocks in total.\n", report.GeneratedAt.Format(time.RFC1123), len(report.Entries), strings.Join(lines, "\n"), report.Days, humanize.Comma(int64(report.TotalBlocks)))
+	if report.Pricing {
+		text = fmt.Sprintf("%sEstimated fuel cost for all monitored POSes: %s\n", text, report.TotalCost)
+	}
+
+	var html bytes.Buffer
+	if err := emailReportTemplate.Execute(&html, report); err != nil {
+		return errors.Wrap(err, "Failed to render report email")
+	}
+
+	subject := fmt.Sprintf("[POSbot] POS fuel report for %s", report.GeneratedAt.Format("2006-01-02"))
+
+	var lastErr error
+	for _, recipient := range n.Recipients {
+		if !recipient.Report {
+			continue
+		}
+
+		if err := n.send(recipient.Address, subject, text, html.String()); err != nil {
+			log.WithField("recipient", recipient.Address).WithError(err).Warn("Failed to send report email")
+			lastErr = err
+			continue
+		}
+
+		log.WithField("recipient", recipient.Address).Info("Report email sent")
+	}
+
+	return lastErr
+}
+
+func (n *EmailNotifier) send(to string, subject string, text string, html string) error {
+	from, err := mail.ParseAddress(n.From)
+	if err != nil {
+		return errors.Wrap(err, "Failed to parse sender address")
+	}
+	recipient, err := mail.ParseAddress(to)
+	if err != nil {
+		return errors.Wrap(err, "Failed to parse recipient address")
+	}
+
+	message, err := buildEmailMessage(from, recipient, subject, text, html)
+	if err != nil {
+		return err
+	}
+
+	host, _, err := net.SplitHostPort(n.Address)
+	if err != nil {
+		return errors.Wrap(err, "Failed to parse SMTP server address")
+	}
+
+	dialer := &net.Dialer{Timeout: time.Second * NotifierTimeout}
+	var conn net.Conn
+	if n.Security == EmailSecurityTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", n.Address, &tls.Config{ServerName: host})
+	} else {
+		conn, err = dialer.Dial("tcp", n.Address)
+	}
+	if err != nil {
+		return errors.Wrap(err, "Failed to connect to SMTP server")
+	}
+
+	// the dialer timeout only covers connecting, a stalled server would block the whole SMTP exchange otherwise
+	if err = conn.SetDeadline(time.Now().Add(time.Second * EmailSessionTimeout)); err != nil {
+		conn.Close()
+		return errors.Wrap(err, "Failed to set SMTP connection deadline")
+	}
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return errors.Wrap(err, "Failed to create SMTP client")
+	}
+	defer client.Close()
+
+	if n.Security == EmailSecuritySTARTTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return errors.New("SMTP server does not support STARTTLS")
+		}
+		if err = client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return errors.Wrap(err, "Failed to start TLS")
+		}
+	}
+
+	if len(n.Username) > 0 {
+		if err = client.Auth(smtp.PlainAuth("", n.Username, n.Password, host)); err != nil {
+			return errors.Wrap(err, "Failed to authenticate with SMTP server")
+		}
+	}
+
+	if err = client.Mail(from.Address); err != nil {
+		return errors.Wrap(err, "Failed to set sender")
+	}
+	if err = client.Rcpt(recipient.Address); err != nil {
+		return errors.Wrap(err, "Failed to set recipient")
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return errors.Wrap(err, "Failed to start message data")
+	}
+	if _, err = w.Write(message); err != nil {
+		w.Close()
+		return errors.Wrap(err, "Failed to write message data")
+	}
+	if err = w.Close(); err != nil {
+		return errors.Wrap(err, "Failed to send message data")
+	}
+
+	return client.Quit()
+}
+
+func buildEmailMessage(from *mail.Address, to *mail.Address, subject string, text string, html string) ([]byte, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	for _, part := range []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=UTF-8", text},
+		{"text/html; charset=UTF-8", html},
+	} {
+		w, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"8bit"},
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to create message part")
+		}
+		if _, err = w.Write([]byte(strings.Replace(part.content, "\n", "\r\n", -1))); err != nil {
+			return nil, errors.Wrap(err, "Failed to write message part")
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, errors.Wrap(err, "Failed to finish message")
+	}
+
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", from.String())
+	fmt.Fprintf(&message, "To: %s\r\n", to.String())
+	fmt.Fprintf(&message, "Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", subject))
+	fmt.Fprintf(&message, "Date: %s\r\n", time.Now().UTC().Format(time.RFC1123Z))
+	fmt.Fprintf(&message, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&message, "Content-Type: multipart/alternative; boundary=%q\r\n", writer.Boundary())
+	fmt.Fprintf(&message, "\r\n")
+	message.Write(body.Bytes())
+
+	return message.Bytes(), nil
+}
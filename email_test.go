@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bufio"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type smtpTestMessage struct {
+	From string
+	To   []string
+	Data string
+}
+
+type smtpTestServer struct {
+	listener net.Listener
+	mutex    sync.Mutex
+	auth     []string
+	messages []*smtpTestMessage
+	wg       sync.WaitGroup
+}
+
+func newSMTPTestServer(t *testing.T) *smtpTestServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+
+	server := &smtpTestServer{listener: listener}
+	server.wg.Add(1)
+	go func() {
+		defer server.wg.Done()
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			server.wg.Add(1)
+			go func() {
+				defer server.wg.Done()
+				server.serve(conn)
+			}()
+		}
+	}()
+
+	return server
+}
+
+func (s *smtpTestServer) Address() string {
+	return s.listener.Addr().String()
+}
+
+func (s *smtpTestServer) Close() {
+	s.listener.Close()
+	s.wg.Wait()
+}
+
+func (s *smtpTestServer) serve(conn net.Conn) {
+	defer conn.Close()
+
+	text := textproto.NewConn(conn)
+	text.PrintfLine("220 localhost ESMTP test")
+
+	message := &smtpTestMessage{}
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+
+		command := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(command, "EHLO"):
+			text.PrintfLine("250-localhost")
+			text.PrintfLine("250 AUTH PLAIN")
+		case strings.HasPrefix(command, "AUTH PLAIN"):
+			s.mutex.Lock()
+			s.auth = append(s.auth, strings.TrimSpace(line[len("AUTH PLAIN"):]))
+			s.mutex.Unlock()
+			text.PrintfLine("235 Authentication successful")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			message.From = strings.Trim(line[len("MAIL FROM:"):], "<>")
+			text.PrintfLine("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			message.To = append(message.To, strings.Trim(line[len("RCPT TO:"):], "<>"))
+			text.PrintfLine("250 OK")
+		case command == "DATA":
+			text.PrintfLine("354 Go ahead")
+			data, err := text.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			message.Data = string(data)
+			s.mutex.Lock()
+			s.messages = append(s.messages, message)
+			s.mutex.Unlock()
+			message = &smtpTestMessage{}
+			text.PrintfLine("250 OK")
+		case command == "QUIT":
+			text.PrintfLine("221 Bye")
+			return
+		default:
+			text.PrintfLine("502 Command not implemented")
+		}
+	}
+}
+
+func TestEmailNotifierNotify(t *testing.T) {
+	server := newSMTPTestServer(t)
+
+	notifier := NewEmailNotifier(server.Address(), "posbot", "secret", "POSbot <posbot@example.com>", EmailSecurityNone, []EmailRecipient{
+		{Address: "all@example.com", Severity: AlertSeverityWarning},
+		{Address: "critical@example.com", Severity: AlertSeverityCritical},
+	})
+
+	alerts := []*FuelAlert{
+		newTestFuelAlert(1, "Moon <1>", AlertSeverityWarning),
+		newTestFuelAlert(2, "Moon 2", AlertSeverityCritical),
+	}
+	if err := notifier.Notify(alerts); err != nil {
+		t.Fatalf("Notify returned error: %v", err)
+	}
+	server.Close()
+
+	if len(server.auth) != 2 {
+		t.Errorf("Expected 2 authentications, got %d", len(server.auth))
+	}
+	if len(server.messages) != 2 {
+		t.Fatalf("Expected 2 messages, got %d", len(server.messages))
+	}
+
+	all := server.messages[0]
+	if all.From != "posbot@example.com" {
+		t.Errorf("Unexpected sender %q", all.From)
+	}
+	if len(all.To) != 1 || all.To[0] != "all@example.com" {
+		t.Errorf("Unexpected recipients %v", all.To)
+	}
+	for _, expected := range []string{
+		"Subject: [POSbot] 2 POS fuel alert(s)",
+		"Content-Type: multipart/alternative;",
+		"- Moon <1> (owned by Test Corporation): 12 hours of Nitrogen Fuel Block left, warning, POS ID 1",
+		"<td>Moon &lt;1&gt;</td>",
+	} {
+		if !strings.Contains(all.Data, expected) {
+			t.Errorf("Expected message to contain %q", expected)
+		}
+	}
+
+	critical := server.messages[1]
+	if len(critical.To) != 1 || critical.To[0] != "critical@example.com" {
+		t.Errorf("Unexpected recipients %v", critical.To)
+	}
+	if !strings.Contains(critical.Data, "Subject: [POSbot] 1 POS fuel alert(s)") || strings.Contains(critical.Data, "POS ID 1") {
+		t.Errorf("Expected only the critical alert in message, got %q", critical.Data)
+	}
+}
+
+func TestEmailNotifierNotifyRejected(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		w := bufio.NewWriter(conn)
+		w.WriteString("554 No SMTP service here\r\n")
+		w.Flush()
+	}()
+
+	notifier := NewEmailNotifier(listener.Addr().String(), "", "", "posbot@example.com", EmailSecurityNone, []EmailRecipient{
+		{Address: "all@example.com", Severity: AlertSeverityWarning},
+	})
+	if err := notifier.Notify([]*FuelAlert{newTestFuelAlert(1, "Moon 1", AlertSeverityWarning)}); err == nil {
+		t.Fatal("Expected error for rejected SMTP connection")
+	}
+}
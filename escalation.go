@@ -0,0 +1,220 @@
+package main
+
+import (
+	"github.com/Sirupsen/logrus"
+	"github.com/garyburd/redigo/redis"
+	"github.com/pkg/errors"
+	"strings"
+	"time"
+)
+
+const (
+	EscalationThresholdWarning  = "warning"
+	EscalationThresholdCritical = "critical"
+)
+
+type EscalationStage struct {
+	Name                 string   `json:"name"`
+	Threshold            string   `json:"threshold"`
+	HoursRemaining       int      `json:"hoursRemaining"`
+	Repeat               int      `json:"repeat"`
+	Mention              string   `json:"mention"`
+	ChannelID            string   `json:"channelID"`
+	DirectMessageUserIDs []string `json:"directMessageUserIDs"`
+}
+
+func (s *EscalationStage) triggerHours(threshold FuelThreshold) int {
+	if strings.EqualFold(s.Threshold, EscalationThresholdWarning) {
+		return threshold.Warning
+	} else if strings.EqualFold(s.Threshold, EscalationThresholdCritical) {
+		return threshold.Critical
+	}
+
+	return s.HoursRemaining
+}
+
+func (s *EscalationStage) hasDirectMessageUser(userID string) bool {
+	for _, id := range s.DirectMessageUserIDs {
+		if strings.EqualFold(id, userID) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func validateEscalationStages(stages []EscalationStage, threshold FuelThreshold) error {
+	// getEscalationStage picks the last matching stage and only the final one is critical, so stages have to get more urgent
+	for i := 1; i < len(stages); i++ {
+		previous, current := stages[i-1].triggerHours(threshold), stages[i].triggerHours(threshold)
+		if current >= previous {
+			return errors.Errorf("Escalation stage %q (%dh) has to trigger below the previous stage %q (%dh)", stages[i].Name, current, stages[i-1].Name, previous)
+		}
+	}
+
+	return nil
+}
+
+func defaultEscalationStages(config *Config) []EscalationStage {
+	return []EscalationStage{
+		{
+			Name:      EscalationThresholdWarning,
+			Threshold: EscalationThresholdWarning,
+			Repeat:    config.Discord.Notifications.Warning,
+			Mention:   "@here",
+		},
+		{
+			Name:      EscalationThresholdCritical,
+			Threshold: EscalationThresholdCritical,
+			Repeat:    config.Discord.Notifications.Critical,
+			Mention:   "@everyone",
+		},
+	}
+}
+
+func (b *Bot) getEscalationStage(threshold FuelThreshold, fuel POSFuel) (int, *EscalationStage) {
+	stageIndex := 0
+	var stage *EscalationStage = nil
+
+	for i := range b.config.Discord.Escalation {
+		s := &b.config.Discord.Escalation[i]
+		if int(fuel.HoursRemaining) <= s.triggerHours(threshold) {
+			stageIndex = i + 1
+			stage = s
+		}
+	}
+
+	return stageIndex, stage
+}
+
+func (b *Bot) getStarbaseEscalationStage(pos *POS) int {
+	threshold := b.getFuelThreshold(pos)
+
+	stageIndex := 0
+	for _, fuel := range pos.Fuel {
+		if !fuel.ConstantlyRequired {
+			continue
+		}
+
+		index, _ := b.getEscalationStage(threshold, fuel)
+		if index > stageIndex {
+			stageIndex = index
+		}
+	}
+
+	return stageIndex
+}
+
+type FuelAlert struct {
+	POS             *POS
+	Fuel            POSFuel
+	Remaining       string
+	StageIndex      int
+	Stage           *EscalationStage
+	Severity        string
+	Acknowledgement *StarbaseAcknowledgement
+}
+
+type AlertHistoryEntry struct {
+	StarbaseID   int       `json:"starbaseID"`
+	LocationName string    `json:"locationName"`
+	OwnerName    string    `json:"ownerName"`
+	FuelTypeID   int       `json:"fuelTypeID"`
+	FuelTypeName string    `json:"fuelTypeName"`
+	Remaining    string    `json:"remaining"`
+	Stage        string    `json:"stage"`
+	Severity     string    `json:"severity"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+func newAlertHistoryEntry(alert *FuelAlert) *AlertHistoryEntry {
+	return &AlertHistoryEntry{
+		StarbaseID:   alert.POS.ID,
+		LocationName: alert.POS.LocationName,
+		OwnerName:    alert.POS.OwnerName,
+		FuelTypeID:   alert.Fuel.TypeID,
+		FuelTypeName: alert.Fuel.TypeName,
+		Remaining:    alert.Remaining,
+		Stage:        alert.Stage.Name,
+		Severity:     alert.Severity,
+		Timestamp:    time.Now().UTC(),
+	}
+}
+
+func (b *Bot) sendDiscordFuelAlerts(alerts []*FuelAlert) {
+	if !b.config.Discord.BatchAlerts {
+		for _, alert := range alerts {
+			b.sendDiscordEscalation(alert)
+			log.WithFields(logrus.Fields{
+				"starbaseID": alert.POS.ID,
+				"fuelTypeID": alert.Fuel.TypeID,
+				"stage":      alert.Stage.Name,
+			}).Info("Notification for escalation stage sent")
+		}
+		b.sendDiscordSubscriptionDirectMessages(alerts)
+		return
+	}
+
+	stages := make(map[int][]*FuelAlert)
+	for _, alert := range alerts {
+		stages[alert.StageIndex] = append(stages[alert.StageIndex], alert)
+	}
+
+	for i := range b.config.Discord.Escalation {
+		stageAlerts, ok := stages[i+1]
+		if !ok {
+			continue
+		}
+
+		b.sendDiscordEscalationDigest(i+1, &b.config.Discord.Escalation[i], stageAlerts)
+		log.WithFields(logrus.Fields{
+			"stage": b.config.Discord.Escalation[i].Name,
+			"count": len(stageAlerts),
+		}).Info("Notification digest for escalation stage sent")
+	}
+
+	b.sendDiscordSubscriptionDirectMessages(alerts)
+}
+
+func (b *Bot) getCurrentFuelAlerts() ([]*FuelAlert, error) {
+	poses, err := b.getMonitoredPOSes()
+	if err != nil {
+		return nil, err
+	}
+
+	alerts := make([]*FuelAlert, 0)
+	for _, pos := range poses {
+		threshold := b.getFuelThreshold(pos)
+
+		ack, err := b.retrieveStarbaseAcknowledgement(pos.ID)
+		if err != nil && err != redis.ErrNil {
+			log.WithField("starbaseID", pos.ID).WithError(err).Warn("Failed to retrieve starbase acknowledgement")
+		}
+
+		for _, fuel := range pos.Fuel {
+			if !fuel.ConstantlyRequired {
+				continue
+			}
+
+			stageIndex, stage := b.getEscalationStage(threshold, fuel)
+			if stage == nil {
+				continue
+			}
+
+			alert := &FuelAlert{
+				POS:        pos,
+				Fuel:       fuel,
+				Remaining:  formatHoursRemaining(fuel.HoursRemaining),
+				StageIndex: stageIndex,
+				Stage:      stage,
+				Severity:   b.getAlertSeverity(stageIndex),
+			}
+			if ack.Covers(stageIndex) {
+				alert.Acknowledgement = ack
+			}
+			alerts = append(alerts, alert)
+		}
+	}
+
+	return alerts, nil
+}
@@ -1,21 +1,43 @@
 package main
 
 import (
+	"encoding/xml"
 	"fmt"
 	"github.com/MorpheusXAUT/durafmt"
 	"github.com/MorpheusXAUT/eveapi"
 	"github.com/Sirupsen/logrus"
 	"github.com/garyburd/redigo/redis"
 	"github.com/pkg/errors"
+	"net/http"
+	"net/url"
 	"strings"
 	"time"
 )
 
+const (
+	EVEAPIKeyInfoURL                  = "https://api.eveonline.com/account/APIKeyInfo.xml.aspx"
+	EVEAPIKeyTypeCorporation          = "Corporation"
+	EVEAPIKeyAccessMaskStarbaseDetail = 131072
+	EVEAPIKeyAccessMaskStarbaseList   = 524288
+	EVEAPIKeyAccessMaskRequired       = EVEAPIKeyAccessMaskStarbaseDetail | EVEAPIKeyAccessMaskStarbaseList
+	EVEAPITimeFormat                  = "2006-01-02 15:04:05"
+	EVEAPIKeyInfoTimeout              = 30
+	DefaultAcknowledgeDuration        = 21600
+	DefaultKeyExpiryWarning           = 7
+)
+
 func (b *Bot) checkStarbaseFuel() {
 	log.Info("Checking starbase fuel")
 
+	start := time.Now()
+	defer func() {
+		metricFuelCheckDuration.Observe(time.Since(start).Seconds())
+	}()
+
 	err := b.updateMonitoredStarbaseDetails()
+	b.health.recordStarbaseUpdate(err == nil)
 	if err != nil {
+		metricFuelCheckFailures.WithLabelValues(MetricsFailureUpdate).Inc()
 		log.WithError(err).Error("Failed to update monitored starbase details")
 		if b.config.Discord.Verbose {
 			b.discord.ChannelMessage(b.config.Discord.ChannelID, ":warning: There was an error updating monitored POSes :warning:")
@@ -23,8 +45,11 @@ func (b *Bot) checkStarbaseFuel() {
 		return
 	}
 
+	b.checkStarbaseListChanges()
+
 	monitored, err := b.getMonitoredStarbaseIDs()
 	if err != nil {
+		metricFuelCheckFailures.WithLabelValues(MetricsFailureList).Inc()
 		log.WithError(err).Error("Failed to retrieve monitored starbases")
 		if b.config.Discord.Verbose {
 			b.discord.ChannelMessage(b.config.Discord.ChannelID, ":warning: There was an error retrieving monitored POSes :warning:")
@@ -32,11 +57,15 @@ func (b *Bot) checkStarbaseFuel() {
 		return
 	}
 
+	metrics := newStarbaseMetrics()
+
+	alerts := make([]*FuelAlert, 0)
 	for _, starbaseID := range monitored {
 		log.WithField("starbaseID", starbaseID).Debug("Checking POS fuel status")
 
 		pos, err := b.getPOSFromStarbaseID(starbaseID)
 		if err != nil {
+			metricFuelCheckFailures.WithLabelValues(MetricsFailureStarbase).Inc()
 			log.WithField("starbaseID", starbaseID).WithError(err).Warn("Failed to get POS from starbaseID")
 			if b.config.Discord.Verbose {
 				b.discord.ChannelMessage(b.config.Discord.ChannelID, fmt.Sprintf(":warning: There was an error retrieving POS #%d :warning:", starbaseID))
@@ -44,6 +73,15 @@ func (b *Bot) checkStarbaseFuel() {
 			continue
 		}
 
+		metrics.Update(pos)
+
+		threshold := b.getFuelThreshold(pos)
+
+		ack, err := b.retrieveStarbaseAcknowledgement(pos.ID)
+		if err != nil && err != redis.ErrNil {
+			log.WithField("starbaseID", pos.ID).WithError(err).Warn("Failed to retrieve starbase acknowledgement")
+		}
+
 		for _, fuel := range pos.Fuel {
 			if !fuel.ConstantlyRequired {
 				continue
@@ -61,50 +99,451 @@ func (b *Bot) checkStarbaseFuel() {
 				continue
 			}
 
-			if int(fuel.HoursRemaining) <= b.config.EVE.FuelThreshold.Critical {
-				if b.shouldSendNotification(pos.ID, fuel.TypeID, 2) {
-					b.discord.ChannelMessageSend(b.config.Discord.ChannelID, fmt.Sprintf("@everyone :rotating_light: POS at **%s** (owned by %s) only has __**%s**__ of fuel **%s** left. FIX THIS SHIT NOW :rage:", pos.LocationName, pos.OwnerName, remaining, fuel.TypeName))
+			stageIndex, stage := b.getEscalationStage(threshold, fuel)
+			if stage == nil {
+				// the escalation key expires after the stage's repeat interval (e.g. while acknowledged), the open alert is tracked separately
+				closed := b.closeFuelAlert(pos.ID, fuel.TypeID)
+				if b.clearEscalation(pos.ID, fuel.TypeID) || closed {
 					log.WithFields(logrus.Fields{
-						"starbaseID":   pos.ID,
-						"fuelTypeID":   fuel.TypeID,
-						"notification": 2,
-					}).Info("Notification for critical fuel status sent")
-				} else {
-					log.WithFields(logrus.Fields{
-						"starbaseID":   pos.ID,
-						"fuelTypeID":   fuel.TypeID,
-						"notification": 2,
-					}).Debug("Notification already sent, skipping critical fuel status")
-				}
-			} else if int(fuel.HoursRemaining) <= b.config.EVE.FuelThreshold.Warning {
-				if b.shouldSendNotification(pos.ID, fuel.TypeID, 1) {
-					b.discord.ChannelMessageSend(b.config.Discord.ChannelID, fmt.Sprintf("@here :alarm_clock: POS at **%s** (owned by %s) has **%s** of fuel **%s** left, someone should probably check that :thinking:", pos.LocationName, pos.OwnerName, remaining, fuel.TypeName))
-					log.WithFields(logrus.Fields{
-						"starbaseID":   pos.ID,
-						"fuelTypeID":   fuel.TypeID,
-						"notification": 1,
-					}).Info("Notification for warning fuel status sent")
-				} else {
-					log.WithFields(logrus.Fields{
-						"starbaseID":   pos.ID,
-						"fuelTypeID":   fuel.TypeID,
-						"notification": 1,
-					}).Debug("Notification already sent, skipping warning fuel status")
+						"starbaseID": pos.ID,
+						"fuelTypeID": fuel.TypeID,
+					}).Info("Fuel alert resolved")
+					b.publishWebhookEvent(WebhookEventFuelResolved, &WebhookFuelResolved{
+						Starbase: newWebhookStarbaseFromPOS(pos),
+						Fuel:     newWebhookFuel(fuel),
+					})
 				}
+				continue
+			}
+
+			b.openFuelAlert(pos.ID, fuel.TypeID)
+
+			if ack.Covers(stageIndex) {
+				log.WithFields(logrus.Fields{
+					"starbaseID":   pos.ID,
+					"fuelTypeID":   fuel.TypeID,
+					"stage":        stage.Name,
+					"acknowledged": ack.UserName,
+				}).Debug("Fuel status acknowledged, skipping escalation stage")
+			} else if b.shouldEscalate(pos.ID, fuel.TypeID, stageIndex, stage) {
+				alerts = append(alerts, &FuelAlert{
+					POS:             pos,
+					Fuel:            fuel,
+					Remaining:       remaining.String(),
+					StageIndex:      stageIndex,
+					Stage:           stage,
+					Severity:        b.getAlertSeverity(stageIndex),
+					Acknowledgement: ack,
+				})
+			} else {
+				log.WithFields(logrus.Fields{
+					"starbaseID": pos.ID,
+					"fuelTypeID": fuel.TypeID,
+					"stage":      stage.Name,
+				}).Debug("Notification already sent, skipping escalation stage")
 			}
 		}
 	}
 
+	metrics.Finish()
+
+	b.sendFuelAlerts(alerts)
+
 	log.Info("Finished checking starbase fuel")
 }
 
+func (b *Bot) checkStarbaseListChanges() {
+	log.Debug("Checking starbase list for changes")
+
+	starbases, err := b.retrieveStarbaseList()
+	if err != nil {
+		log.WithError(err).Error("Failed to retrieve starbase list for change detection")
+		return
+	}
+
+	previous, err := b.retrieveStarbaseSnapshot()
+	if err == redis.ErrNil {
+		log.Debug("No previous starbase snapshot found, storing current starbase list")
+		if err = b.storeStarbaseSnapshot(starbases); err != nil {
+			log.WithError(err).Warn("Failed to store starbase snapshot")
+		}
+		return
+	} else if err != nil {
+		log.WithError(err).Error("Failed to retrieve previous starbase snapshot")
+		return
+	}
+
+	// an empty list usually means an API glitch or changed key permissions rather than every tower being killed
+	if len(starbases.Starbases) == 0 && len(previous.Starbases) > 0 {
+		log.WithField("previous", len(previous.Starbases)).Warn("Retrieved empty starbase list, keeping previous snapshot")
+		return
+	}
+
+	added, removed := diffStarbaseLists(previous, starbases)
+	for _, starbase := range added {
+		log.WithField("starbaseID", starbase.ID).Info("Detected newly anchored starbase")
+		b.notifyDiscordStarbaseAdded(starbase)
+		b.publishWebhookEvent(WebhookEventStarbaseAdded, &WebhookStarbaseChange{
+			Starbase: newWebhookStarbase(starbase),
+		})
+	}
+	for _, starbase := range removed {
+		log.WithFields(logrus.Fields{
+			"starbaseID": starbase.ID,
+			"state":      starbase.State,
+		}).Info("Detected removed starbase")
+		b.notifyDiscordStarbaseRemoved(starbase)
+		b.publishWebhookEvent(WebhookEventStarbaseRemoved, &WebhookStarbaseChange{
+			Starbase: newWebhookStarbase(starbase),
+		})
+	}
+
+	changed := diffStarbaseStates(previous, starbases)
+	for starbase, previousState := range changed {
+		log.WithFields(logrus.Fields{
+			"starbaseID":    starbase.ID,
+			"previousState": previousState,
+			"state":         starbase.State,
+		}).Info("Detected starbase state change")
+		b.publishWebhookEvent(WebhookEventStateChanged, &WebhookStarbaseChange{
+			Starbase:      newWebhookStarbase(starbase),
+			PreviousState: previousState.String(),
+		})
+	}
+
+	if err = b.storeStarbaseSnapshot(starbases); err != nil {
+		log.WithError(err).Warn("Failed to store starbase snapshot")
+	}
+
+	log.WithFields(logrus.Fields{
+		"added":   len(added),
+		"removed": len(removed),
+		"changed": len(changed),
+	}).Debug("Finished checking starbase list for changes")
+}
+
+func diffStarbaseLists(previous *eveapi.StarbaseList, current *eveapi.StarbaseList) ([]*eveapi.Starbase, []*eveapi.Starbase) {
+	previousIDs := make(map[int]bool)
+	for _, starbase := range previous.Starbases {
+		previousIDs[starbase.ID] = true
+	}
+	currentIDs := make(map[int]bool)
+	for _, starbase := range current.Starbases {
+		currentIDs[starbase.ID] = true
+	}
+
+	added := make([]*eveapi.Starbase, 0)
+	for _, starbase := range current.Starbases {
+		if !previousIDs[starbase.ID] {
+			added = append(added, starbase)
+		}
+	}
+
+	removed := make([]*eveapi.Starbase, 0)
+	for _, starbase := range previous.Starbases {
+		if !currentIDs[starbase.ID] {
+			removed = append(removed, starbase)
+		}
+	}
+
+	return added, removed
+}
+
+func diffStarbaseStates(previous *eveapi.StarbaseList, current *eveapi.StarbaseList) map[*eveapi.Starbase]eveapi.StarbaseState {
+	previousStates := make(map[int]eveapi.StarbaseState)
+	for _, starbase := range previous.Starbases {
+		previousStates[starbase.ID] = starbase.State
+	}
+
+	changed := make(map[*eveapi.Starbase]eveapi.StarbaseState)
+	for _, starbase := range current.Starbases {
+		if state, ok := previousStates[starbase.ID]; ok && state != starbase.State {
+			changed[starbase] = state
+		}
+	}
+
+	return changed
+}
+
+func (b *Bot) checkAPIKey() {
+	log.Info("Checking EVE API key")
+
+	keyInfo, err := b.retrieveAPIKeyInfo()
+	if err != nil {
+		log.WithError(err).Error("Failed to retrieve EVE API key info")
+		if b.config.Discord.Verbose {
+			b.discord.ChannelMessageSend(b.config.Discord.ChannelID, ":warning: There was an error retrieving the EVE API key info :warning:")
+		}
+		return
+	}
+
+	err = validateAPIKeyInfo(keyInfo)
+	if err != nil {
+		log.WithError(err).Error("EVE API key is no longer valid")
+		b.discord.ChannelMessageSend(b.config.Discord.ChannelID, fmt.Sprintf("@here :key: The EVE API key I'm using is not valid anymore (%s). I'm blind without it, someone please provide a new one :see_no_evil:", err))
+		return
+	}
+
+	if !keyInfo.Expires.IsZero() {
+		remaining := keyInfo.Expires.Sub(time.Now().UTC())
+		if remaining <= time.Duration(b.config.EVE.KeyExpiryWarning)*24*time.Hour {
+			b.discord.ChannelMessageSend(b.config.Discord.ChannelID, fmt.Sprintf("@here :key: The EVE API key I'm using expires in **%s** (%s). Someone should create a new one before I go blind :see_no_evil:", durafmt.Parse(remaining).Short(), keyInfo.Expires.Format(time.RFC1123)))
+			log.WithFields(logrus.Fields{
+				"keyID":   b.config.EVE.KeyID,
+				"expires": keyInfo.Expires,
+			}).Info("Notification for expiring EVE API key sent")
+		}
+	}
+
+	log.Info("Finished checking EVE API key")
+}
+
+type APIKeyInfo struct {
+	AccessMask      int
+	Type            string
+	Expires         time.Time
+	CorporationID   int
+	CorporationName string
+}
+
+type apiKeyInfoResponse struct {
+	Error *struct {
+		Code    int    `xml:"code,attr"`
+		Message string `xml:",chardata"`
+	} `xml:"error"`
+	Key struct {
+		AccessMask int    `xml:"accessMask,attr"`
+		Type       string `xml:"type,attr"`
+		Expires    string `xml:"expires,attr"`
+		Characters []struct {
+			CorporationID   int    `xml:"corporationID,attr"`
+			CorporationName string `xml:"corporationName,attr"`
+		} `xml:"rowset>row"`
+	} `xml:"result>key"`
+}
+
+func (b *Bot) retrieveAPIKeyInfo() (*APIKeyInfo, error) {
+	log.WithField("keyID", b.config.EVE.KeyID).Debug("Retrieving EVE API key info")
+
+	params := url.Values{}
+	params.Set("keyID", b.config.EVE.KeyID)
+	params.Set("vCode", b.config.EVE.KeyVCode)
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s?%s", EVEAPIKeyInfoURL, params.Encode()), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create API key info request")
+	}
+	req.Header.Set("User-Agent", UserAgent)
+
+	// bypass the httpcache client, validation should always reflect the current state of the key
+	client := &http.Client{Timeout: time.Second * EVEAPIKeyInfoTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to query API key info")
+	}
+	defer resp.Body.Close()
+
+	response := &apiKeyInfoResponse{}
+	err = xml.NewDecoder(resp.Body).Decode(response)
+	if response.Error != nil {
+		return nil, errors.Errorf("EVE API returned error %d: %s", response.Error.Code, strings.TrimSpace(response.Error.Message))
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("EVE API returned unexpected status %d for API key info", resp.StatusCode)
+	}
+
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to parse API key info")
+	}
+
+	keyInfo := &APIKeyInfo{
+		AccessMask: response.Key.AccessMask,
+		Type:       response.Key.Type,
+	}
+
+	if len(response.Key.Expires) > 0 {
+		keyInfo.Expires, err = time.Parse(EVEAPITimeFormat, response.Key.Expires)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to parse API key expiry")
+		}
+	}
+
+	if len(response.Key.Characters) > 0 {
+		keyInfo.CorporationID = response.Key.Characters[0].CorporationID
+		keyInfo.CorporationName = response.Key.Characters[0].CorporationName
+	}
+
+	log.WithFields(logrus.Fields{
+		"keyID":       b.config.EVE.KeyID,
+		"accessMask":  keyInfo.AccessMask,
+		"type":        keyInfo.Type,
+		"expires":     keyInfo.Expires,
+		"corporation": keyInfo.CorporationName,
+	}).Debug("Retrieved EVE API key info")
+	return keyInfo, nil
+}
+
+func validateAPIKeyInfo(keyInfo *APIKeyInfo) error {
+	if !strings.EqualFold(keyInfo.Type, EVEAPIKeyTypeCorporation) {
+		return errors.Errorf("API key has type %q, corporation key required", keyInfo.Type)
+	}
+	if keyInfo.AccessMask&EVEAPIKeyAccessMaskStarbaseList == 0 {
+		return errors.New("API key is missing StarbaseList access")
+	}
+	if keyInfo.AccessMask&EVEAPIKeyAccessMaskStarbaseDetail == 0 {
+		return errors.New("API key is missing StarbaseDetail access")
+	}
+	if !keyInfo.Expires.IsZero() && keyInfo.Expires.Before(time.Now().UTC()) {
+		return errors.Errorf("API key expired at %s", keyInfo.Expires.Format(time.RFC1123))
+	}
+
+	return nil
+}
+
 func (b *Bot) isStarbaseMonitored(starbaseID int) bool {
+	if b.isStarbaseIgnoredByConfig(starbaseID) {
+		return false
+	}
+
+	ignore, err := b.retrieveStarbaseIgnore(starbaseID)
+	if err != nil && err != redis.ErrNil {
+		log.WithField("starbaseID", starbaseID).WithError(err).Warn("Failed to retrieve starbase ignore, assuming monitored")
+		return true
+	}
+
+	return ignore == nil
+}
+
+func (b *Bot) isKnownStarbaseID(starbaseID int) bool {
+	starbases, err := b.retrieveStarbaseList()
+	if err != nil {
+		log.WithField("starbaseID", starbaseID).WithError(err).Warn("Failed to retrieve starbase list, assuming known starbase")
+		return true
+	}
+
+	for _, starbase := range starbases.Starbases {
+		if starbase.ID == starbaseID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (b *Bot) isStarbaseIgnoredByConfig(starbaseID int) bool {
 	for _, id := range b.config.EVE.IgnoredStarbases {
 		if starbaseID == id {
-			return false
+			return true
 		}
 	}
-	return true
+	return false
+}
+
+type FuelThreshold struct {
+	Warning  int
+	Critical int
+	Source   string
+}
+
+func (b *Bot) getFuelThreshold(pos *POS) FuelThreshold {
+	threshold := FuelThreshold{
+		Warning:  b.config.EVE.FuelThreshold.Warning,
+		Critical: b.config.EVE.FuelThreshold.Critical,
+		Source:   "default",
+	}
+
+	specificity := 0
+	for _, override := range b.config.EVE.FuelThreshold.Overrides {
+		s, source := matchFuelThresholdOverride(override, pos)
+		if s <= specificity {
+			continue
+		}
+
+		specificity = s
+		threshold.Source = source
+		threshold.Warning = b.config.EVE.FuelThreshold.Warning
+		threshold.Critical = b.config.EVE.FuelThreshold.Critical
+		if override.Warning > 0 {
+			threshold.Warning = override.Warning
+		}
+		if override.Critical > 0 {
+			threshold.Critical = override.Critical
+		}
+	}
+
+	runtime, err := b.retrieveStarbaseThreshold(pos.ID)
+	if err != nil && err != redis.ErrNil {
+		log.WithField("starbaseID", pos.ID).WithError(err).Warn("Failed to retrieve starbase threshold override")
+	} else if runtime != nil {
+		threshold.Source = "runtime override"
+		if runtime.Warning > 0 {
+			threshold.Warning = runtime.Warning
+		}
+		if runtime.Critical > 0 {
+			threshold.Critical = runtime.Critical
+		}
+	}
+
+	return threshold
+}
+
+func matchFuelThresholdOverride(override FuelThresholdOverride, pos *POS) (int, string) {
+	specificity := 0
+	source := ""
+
+	if len(override.Size) > 0 {
+		if !strings.EqualFold(override.Size, pos.Size.String()) {
+			return 0, ""
+		}
+		specificity, source = 1, "size override"
+	}
+	if len(override.Region) > 0 {
+		if !strings.EqualFold(override.Region, pos.RegionName) {
+			return 0, ""
+		}
+		specificity, source = 2, "region override"
+	}
+	if override.CorporationID > 0 {
+		if override.CorporationID != pos.OwnerID {
+			return 0, ""
+		}
+		specificity, source = 3, "corporation override"
+	}
+	if override.StarbaseID > 0 {
+		if override.StarbaseID != pos.ID {
+			return 0, ""
+		}
+		specificity, source = 4, "starbase override"
+	}
+
+	return specificity, source
+}
+
+type StarbaseAcknowledgement struct {
+	StarbaseID     int
+	UserID         string
+	UserName       string
+	Stage          int
+	AcknowledgedAt time.Time
+	Expires        time.Time
+}
+
+func (a *StarbaseAcknowledgement) Covers(stage int) bool {
+	return a != nil && stage <= a.Stage && a.Expires.After(time.Now().UTC())
+}
+
+type StarbaseIgnore struct {
+	StarbaseID int
+	UserID     string
+	UserName   string
+	Reason     string
+	IgnoredAt  time.Time
+	Expires    time.Time
+}
+
+func (i *StarbaseIgnore) Expired() bool {
+	return !i.Expires.IsZero() && i.Expires.Before(time.Now().UTC())
 }
 
 func (b *Bot) getMonitoredStarbaseIDs() ([]int, error) {
@@ -132,12 +571,16 @@ func (b *Bot) retrieveStarbaseList() (*eveapi.StarbaseList, error) {
 	}
 
 	if err != redis.ErrNil && starbases != nil {
+		recordCacheLookup(MetricsCacheStarbases, true)
 		log.Debug("Retrieved starbase list from cache")
 		return starbases, nil
 	}
+	recordCacheLookup(MetricsCacheStarbases, false)
 
 	log.Debug("Retrieving starbase list from EVE API")
+	start := time.Now()
 	starbases, err = b.eve.CorpStarbaseList()
+	observeEVEAPIRequest("CorpStarbaseList", start, err)
 	if err != nil {
 		return nil, errors.Wrap(err, "Failed to retrieve starbase list from EVE API")
 	}
@@ -160,12 +603,16 @@ func (b *Bot) retrieveStarbaseDetails(starbaseID int) (*eveapi.StarbaseDetails,
 	}
 
 	if err != redis.ErrNil && starbase != nil {
+		recordCacheLookup(MetricsCacheDetails, true)
 		log.WithField("starbaseID", starbaseID).Debug("Retrieved starbase details from cache")
 		return starbase, nil
 	}
+	recordCacheLookup(MetricsCacheDetails, false)
 
 	log.WithField("starbaseID", starbaseID).Debug("Retrieving starbase details from EVE API")
+	start := time.Now()
 	starbase, err = b.eve.CorpStarbaseDetails(starbaseID)
+	observeEVEAPIRequest("CorpStarbaseDetails", start, err)
 	if err != nil {
 		return nil, errors.Wrap(err, "Failed to retrieve starbase details from EVE API")
 	}
@@ -175,12 +622,16 @@ func (b *Bot) retrieveStarbaseDetails(starbaseID int) (*eveapi.StarbaseDetails,
 		log.WithField("starbaseID", starbaseID).WithError(err).Warn("Failed to cache starbase details")
 	}
 
+	b.checkStarbaseRefuels(starbaseID, starbase)
+
 	log.WithField("starbaseID", starbaseID).Debug("Retrieved starbase details from EVE API")
 	return starbase, nil
 }
 
 func (b *Bot) getCorporationNameFromID(corporationID int) (string, error) {
+	start := time.Now()
 	names, _, err := b.esi.CorporationApi.GetCorporationsNames([]int64{int64(corporationID)}, nil)
+	observeEVEAPIRequest("GetCorporationsNames", start, err)
 	if err != nil {
 		return "", errors.Wrap(err, "Failed to get corporation name from ID")
 	}
@@ -218,16 +669,53 @@ func (b *Bot) updateMonitoredStarbaseDetails() error {
 }
 
 type POS struct {
-	ID           int
-	LocationID   int
-	LocationName string
-	OwnerID      int
-	OwnerName    string
-	State        eveapi.StarbaseState
-	Monitored    bool
-	CachedUntil  time.Time
-	Size         POSSize
-	Fuel         []POSFuel
+	ID              int
+	LocationID      int
+	LocationName    string
+	SolarSystemID   int
+	SolarSystemName string
+	RegionID        int
+	RegionName      string
+	OwnerID         int
+	OwnerName       string
+	State           eveapi.StarbaseState
+	StateTimestamp  time.Time
+	OnlineTimestamp time.Time
+	Monitored       bool
+	CachedUntil     time.Time
+	Size            POSSize
+	Fuel            []POSFuel
+}
+
+func (p *POS) HoursRemaining() float64 {
+	hours := -1.0
+	for _, fuel := range p.Fuel {
+		if !fuel.ConstantlyRequired {
+			continue
+		}
+		if hours < 0 || fuel.HoursRemaining < hours {
+			hours = fuel.HoursRemaining
+		}
+	}
+
+	if hours < 0 {
+		return 0
+	}
+	return hours
+}
+
+func (p *POS) FuelBlocksRequired(days int) int {
+	required := 0
+	for _, fuel := range p.Fuel {
+		if !fuel.ConstantlyRequired || fuel.Type != POSFuelTypeFuelBlock {
+			continue
+		}
+
+		missing, _ := fuel.Missing(p.Size, days)
+		required += missing
+	}
+
+	return required
 }
 
 type POSSize int
@@ -261,6 +749,29 @@ type POSFuel struct {
 	HoursRemaining     float64
 }
 
+func (f POSFuel) Missing(size POSSize, days int) (int, bool) {
+	capacity := fuelBayCapacityForSize(f.Type, size)
+	if f.Type == POSFuelTypeStrontium {
+		// strontium is only consumed while reinforced, keeping the bay full provides the longest possible reinforcement timer
+		if capacity <= f.Quantity {
+			return 0, false
+		}
+		return capacity - f.Quantity, false
+	}
+
+	target := days * 24 * f.Required
+	capped := false
+	if capacity > 0 && target > capacity {
+		target = capacity
+		capped = true
+	}
+
+	if target <= f.Quantity {
+		return 0, capped
+	}
+	return target - f.Quantity, capped
+}
+
 type POSFuelType int
 
 const (
@@ -268,6 +779,112 @@ const (
 	POSFuelTypeStrontium
 )
 
+func (b *Bot) getMonitoredPOSes() ([]*POS, error) {
+	monitored, err := b.getMonitoredStarbaseIDs()
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to retrieve monitored starbases")
+	}
+
+	poses := make([]*POS, 0)
+	for _, id := range monitored {
+		pos, err := b.getPOSFromStarbaseID(id)
+		if err != nil {
+			log.WithField("starbaseID", id).WithError(err).Warn("Failed to get POS from starbaseID")
+			continue
+		}
+
+		pos.Monitored = true
+		poses = append(poses, pos)
+	}
+
+	return poses, nil
+}
+
+func (b *Bot) getAllPOSes() ([]*POS, error) {
+	starbases, err := b.retrieveStarbaseList()
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to retrieve starbase list")
+	}
+
+	poses := make([]*POS, 0)
+	for _, starbase := range starbases.Starbases {
+		pos, err := b.getPOSFromStarbaseID(starbase.ID)
+		if err != nil {
+			log.WithField("starbaseID", starbase.ID).WithError(err).Warn("Failed to get POS from starbaseID")
+			continue
+		}
+
+		pos.Monitored = b.isStarbaseMonitored(pos.ID)
+		poses = append(poses, pos)
+	}
+
+	return poses, nil
+}
+
+func (b *Bot) newPOSFromStarbase(starbase *eveapi.Starbase, cachedUntil time.Time) *POS {
+	locationName, err := b.getLocationNameFromMoonID(starbase.MoonID)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"starbaseID": starbase.ID,
+			"locationID": starbase.MoonID,
+		}).WithError(err).Warn("Failed to retrieve location name for POS")
+		locationName = fmt.Sprintf("*unknown location - %d*", starbase.MoonID)
+	}
+
+	moonLocation, err := b.getMoonLocationFromMoonID(starbase.MoonID)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"starbaseID": starbase.ID,
+			"locationID": starbase.MoonID,
+		}).WithError(err).Warn("Failed to retrieve moon location for POS")
+		moonLocation = &MoonLocation{
+			SolarSystemName: fmt.Sprintf("*unknown system - %d*", starbase.LocationID),
+			SolarSystemID:   starbase.LocationID,
+			RegionName:      "*unknown region*",
+		}
+	}
+
+	corporationName, err := b.getCorporationNameFromID(starbase.StandingOwnerID)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"starbaseID":    starbase.ID,
+			"corporationID": starbase.StandingOwnerID,
+		}).WithError(err).Warn("Failed to get corporation name for POS")
+		corporationName = fmt.Sprintf("*unknown corporation - %d*", starbase.StandingOwnerID)
+	}
+
+	return &POS{
+		ID:              starbase.ID,
+		LocationID:      starbase.LocationID,
+		LocationName:    locationName,
+		SolarSystemID:   moonLocation.SolarSystemID,
+		SolarSystemName: moonLocation.SolarSystemName,
+		RegionID:        moonLocation.RegionID,
+		RegionName:      moonLocation.RegionName,
+		OwnerID:         starbase.StandingOwnerID,
+		OwnerName:       corporationName,
+		State:           starbase.State,
+		StateTimestamp:  starbase.StateTimestamp.Time,
+		OnlineTimestamp: starbase.OnlineTimestamp.Time,
+		Monitored:       b.isStarbaseMonitored(starbase.ID),
+		CachedUntil:     cachedUntil,
+	}
+}
+
+func (b *Bot) getStarbaseListPOSes() ([]*POS, error) {
+	starbases, err := b.retrieveStarbaseList()
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to retrieve starbase list")
+	}
+
+	poses := make([]*POS, 0, len(starbases.Starbases))
+	for _, starbase := range starbases.Starbases {
+		poses = append(poses, b.newPOSFromStarbase(starbase, starbases.CachedUntil.Time))
+	}
+
+	return poses, nil
+}
+
 func (b *Bot) getPOSFromStarbaseID(starbaseID int) (*POS, error) {
 	log.WithField("starbaseID", starbaseID).Debug("Retrieving POS")
 
@@ -277,9 +894,11 @@ func (b *Bot) getPOSFromStarbaseID(starbaseID int) (*POS, error) {
 	}
 
 	if err != redis.ErrNil && pos != nil {
+		recordCacheLookup(MetricsCachePOS, true)
 		log.WithField("starbaseID", starbaseID).Debug("Retrieved POS from cache")
 		return pos, nil
 	}
+	recordCacheLookup(MetricsCachePOS, false)
 
 	starbases, err := b.retrieveStarbaseList()
 	if err != nil {
@@ -302,7 +921,9 @@ func (b *Bot) getPOSFromStarbaseID(starbaseID int) (*POS, error) {
 		return nil, errors.Wrap(err, "Failed to retrieve starbase details")
 	}
 
+	start := time.Now()
 	starbaseType, _, err := b.esi.UniverseApi.GetUniverseTypesTypeId(int32(starbase.TypeID), nil)
+	observeEVEAPIRequest("GetUniverseTypesTypeId", start, err)
 	if err != nil {
 		return nil, errors.Wrap(err, "Failed to retrieve starbase type details")
 	}
@@ -314,27 +935,11 @@ func (b *Bot) getPOSFromStarbaseID(starbaseID int) (*POS, error) {
 		size = POSSizeMedium
 	}
 
-	locationName, err := b.getLocationNameFromMoonID(starbase.MoonID)
-	if err != nil {
-		log.WithFields(logrus.Fields{
-			"starbaseID": starbase.ID,
-			"locationID": starbase.MoonID,
-		}).WithError(err).Warn("Failed to retrieve location name for POS")
-		locationName = fmt.Sprintf("*unknown location - %d*", starbase.MoonID)
-	}
-
-	corporationName, err := b.getCorporationNameFromID(starbase.StandingOwnerID)
-	if err != nil {
-		log.WithFields(logrus.Fields{
-			"starbaseID":    starbase.ID,
-			"corporationID": starbase.StandingOwnerID,
-		}).WithError(err).Warn("Failed to get corporation name for POS")
-		corporationName = fmt.Sprintf("*unknown corporation - %d*", starbase.StandingOwnerID)
-	}
-
 	posFuel := make([]POSFuel, 0)
 	for _, fuel := range starbaseDetails.Fuel {
+		start = time.Now()
 		typeName, _, err := b.esi.UniverseApi.GetUniverseTypesTypeId(int32(fuel.TypeID), nil)
+		observeEVEAPIRequest("GetUniverseTypesTypeId", start, err)
 		if err != nil {
 			log.WithFields(logrus.Fields{
 				"starbaseID": starbase.ID,
@@ -364,23 +969,12 @@ func (b *Bot) getPOSFromStarbaseID(starbaseID int) (*POS, error) {
 		})
 	}
 
-	cachedUntil := starbaseDetails.CachedUntil.Time
-	if starbaseDetails.CachedUntil.Time.After(starbases.CachedUntil.Time) {
-		cachedUntil = starbases.CachedUntil.Time
-	}
-
-	pos = &POS{
-		ID:           starbase.ID,
-		LocationID:   starbase.LocationID,
-		LocationName: locationName,
-		OwnerID:      starbase.StandingOwnerID,
-		OwnerName:    corporationName,
-		State:        starbase.State,
-		Monitored:    true,
-		CachedUntil:  cachedUntil,
-		Size:         size,
-		Fuel:         posFuel,
+	pos = b.newPOSFromStarbase(starbase, starbases.CachedUntil.Time)
+	if starbaseDetails.CachedUntil.Time.Before(pos.CachedUntil) {
+		pos.CachedUntil = starbaseDetails.CachedUntil.Time
 	}
+	pos.Size = size
+	pos.Fuel = posFuel
 
 	err = b.cachePOS(pos)
 	if err != nil {
@@ -408,6 +1002,40 @@ var (
 	}
 )
 
+var (
+	starbaseFuelBayVolume map[POSSize]map[POSFuelType]float64 = map[POSSize]map[POSFuelType]float64{
+		POSSizeSmall: {
+			POSFuelTypeFuelBlock: 35000,
+			POSFuelTypeStrontium: 12500,
+		},
+		POSSizeMedium: {
+			POSFuelTypeFuelBlock: 70000,
+			POSFuelTypeStrontium: 25000,
+		},
+		POSSizeLarge: {
+			POSFuelTypeFuelBlock: 140000,
+			POSFuelTypeStrontium: 50000,
+		},
+	}
+	starbaseFuelUnitVolume map[POSFuelType]float64 = map[POSFuelType]float64{
+		POSFuelTypeFuelBlock: 5,
+		POSFuelTypeStrontium: 3,
+	}
+)
+
+func fuelBayCapacityForSize(fuelType POSFuelType, size POSSize) int {
+	volume, ok := starbaseFuelBayVolume[size][fuelType]
+	if !ok {
+		return 0
+	}
+
+	return int(volume / fuelUnitVolume(fuelType))
+}
+
+func fuelUnitVolume(fuelType POSFuelType) float64 {
+	return starbaseFuelUnitVolume[fuelType]
+}
+
 func requiredFuelForSize(fuelType POSFuelType, size POSSize) int {
 	required, ok := requiredStarbaseFuel[size][fuelType]
 	if !ok {
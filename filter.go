@@ -0,0 +1,165 @@
+package main
+
+import (
+	"github.com/MorpheusXAUT/eveapi"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	POSSortRemaining = "remaining"
+	POSSortName      = "name"
+	POSSortSystem    = "system"
+)
+
+var posFilterStates = map[string]eveapi.StarbaseState{
+	"unanchored": eveapi.StarbaseStateUnanchored,
+	"anchored":   eveapi.StarbaseStateAnchored,
+	"onlining":   eveapi.StarbaseStateOnlining,
+	"reinforced": eveapi.StarbaseStateReinforced,
+	"online":     eveapi.StarbaseStateOnline,
+}
+
+type POSFilter struct {
+	State       string
+	Corporation string
+	Region      string
+	Size        string
+	Below       int
+	Sort        string
+	Monitored   *bool
+}
+
+func posFilterArguments() []*CommandArgument {
+	return []*CommandArgument{
+		{Name: "state", Type: CommandArgumentText, Description: "only show POSes in this state", Named: true, Choices: []string{"online", "onlining", "reinforced", "anchored", "unanchored"}},
+		{Name: "corp", Type: CommandArgumentText, Description: "only show POSes owned by this corporation (name or ID)", Named: true},
+		{Name: "region", Type: CommandArgumentText, Description: "only show POSes in this region", Named: true},
+		{Name: "size", Type: CommandArgumentText, Description: "only show POSes of this size", Named: true, Choices: []string{"small", "medium", "large"}},
+		{Name: "below", Type: CommandArgumentHours, Description: "only show POSes with less fuel remaining, e.g. 48h or 7d", Named: true},
+		{Name: "sort", Type: CommandArgumentText, Description: "sort POSes by remaining fuel, name or system", Named: true, Choices: []string{POSSortRemaining, POSSortName, POSSortSystem}},
+		{Name: "monitored", Type: CommandArgumentFlag, Description: "only show monitored (true) or ignored (false) POSes", Named: true},
+	}
+}
+
+func parsePOSFilter(ctx *CommandContext) *POSFilter {
+	filter := &POSFilter{
+		State:       strings.ToLower(ctx.String("state")),
+		Corporation: ctx.String("corp"),
+		Region:      ctx.String("region"),
+		Size:        strings.ToLower(ctx.String("size")),
+		Below:       ctx.Int("below"),
+		Sort:        strings.ToLower(ctx.String("sort")),
+	}
+	if ctx.Has("monitored") {
+		monitored := ctx.Bool("monitored")
+		filter.Monitored = &monitored
+	}
+
+	return filter
+}
+
+func (f *POSFilter) Active() bool {
+	return len(f.State) > 0 || len(f.Corporation) > 0 || len(f.Region) > 0 || len(f.Size) > 0 || f.Below > 0 || f.Monitored != nil
+}
+
+func (f *POSFilter) RequiresDetails() bool {
+	return len(f.Size) > 0 || f.Below > 0 || f.Sort == POSSortRemaining
+}
+
+func (f *POSFilter) Matches(pos *POS) bool {
+	if len(f.State) > 0 {
+		state, ok := posFilterStates[f.State]
+		if !ok || pos.State != state {
+			return false
+		}
+	}
+	if len(f.Corporation) > 0 && !strings.EqualFold(f.Corporation, strconv.Itoa(pos.OwnerID)) && !strings.Contains(strings.ToLower(pos.OwnerName), strings.ToLower(normalizeFilterValue(f.Corporation))) {
+		return false
+	}
+	if len(f.Region) > 0 && !strings.EqualFold(pos.RegionName, normalizeFilterValue(f.Region)) {
+		return false
+	}
+	if len(f.Size) > 0 && !strings.EqualFold(pos.Size.String(), f.Size) {
+		return false
+	}
+	if f.Below > 0 && pos.HoursRemaining() >= float64(f.Below) {
+		return false
+	}
+	if f.Monitored != nil && pos.Monitored != *f.Monitored {
+		return false
+	}
+
+	return true
+}
+
+func (f *POSFilter) Apply(poses []*POS) []*POS {
+	filtered := make([]*POS, 0)
+	for _, pos := range poses {
+		if f.Matches(pos) {
+			filtered = append(filtered, pos)
+		}
+	}
+
+	switch f.Sort {
+	case POSSortRemaining:
+		sort.SliceStable(filtered, func(i, j int) bool {
+			return filtered[i].HoursRemaining() < filtered[j].HoursRemaining()
+		})
+	case POSSortName:
+		sort.SliceStable(filtered, func(i, j int) bool {
+			return filtered[i].LocationName < filtered[j].LocationName
+		})
+	case POSSortSystem:
+		sort.SliceStable(filtered, func(i, j int) bool {
+			if filtered[i].SolarSystemName == filtered[j].SolarSystemName {
+				return filtered[i].LocationName < filtered[j].LocationName
+			}
+			return filtered[i].SolarSystemName < filtered[j].SolarSystemName
+		})
+	}
+
+	return filtered
+}
+
+func normalizeFilterValue(value string) string {
+	return strings.Replace(value, "_", " ", -1)
+}
+
+func (b *Bot) getFilteredPOSes(filter *POSFilter) ([]*POS, int, error) {
+	var poses []*POS
+	var err error
+	if filter.Monitored != nil && *filter.Monitored {
+		poses, err = b.getMonitoredPOSes()
+	} else {
+		poses, err = b.getAllPOSes()
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return filter.Apply(poses), len(poses), nil
+}
+
+func (b *Bot) getFilteredStarbaseList(filter *POSFilter) ([]*POS, int, error) {
+	poses, err := b.getStarbaseListPOSes()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if filter.RequiresDetails() {
+		for i, pos := range poses {
+			detailed, err := b.getPOSFromStarbaseID(pos.ID)
+			if err != nil {
+				log.WithField("starbaseID", pos.ID).WithError(err).Warn("Failed to get POS details for filtered starbase list")
+				continue
+			}
+
+			detailed.Monitored = pos.Monitored
+			poses[i] = detailed
+		}
+	}
+
+	return filter.Apply(poses), len(poses), nil
+}
@@ -0,0 +1,38 @@
+module github.com/MorpheusXAUT/POSbot
+
+go 1.17
+
+require (
+	github.com/Sirupsen/logrus v1.0.6
+	github.com/bwmarrin/discordgo v0.28.1
+	github.com/dustin/go-humanize v1.0.1
+	github.com/garyburd/redigo v1.6.0
+	github.com/go-sql-driver/mysql v1.7.1
+	github.com/gregjones/httpcache v0.0.0-20190611155906-901d90724c79
+	github.com/jmoiron/sqlx v1.3.5
+	github.com/pkg/errors v0.9.1
+	github.com/prometheus/client_golang v1.12.2
+	github.com/rifflock/lfshook v0.0.0-20180920164130-b9218ef580f5
+	github.com/shirou/gopsutil v2.20.5+incompatible
+)
+
+require (
+	github.com/StackExchange/wmi v1.2.1 // indirect
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/cespare/xxhash/v2 v2.1.2 // indirect
+	github.com/go-ole/go-ole v1.2.5 // indirect
+	github.com/golang/protobuf v1.5.2 // indirect
+	github.com/gorilla/websocket v1.4.2 // indirect
+	github.com/konsorten/go-windows-terminal-sequences v1.0.3 // indirect
+	github.com/matttproud/golang_protobuf_extensions v1.0.1 // indirect
+	github.com/prometheus/client_model v0.2.0 // indirect
+	github.com/prometheus/common v0.32.1 // indirect
+	github.com/prometheus/procfs v0.7.3 // indirect
+	github.com/sirupsen/logrus v1.6.0 // indirect
+	golang.org/x/crypto v0.0.0-20210421170649-83a5a9bb288b // indirect
+	golang.org/x/sys v0.0.0-20220114195835-da31bd327af9 // indirect
+	golang.org/x/term v0.0.0-20201126162022-7de9c90e9dd1 // indirect
+	google.golang.org/protobuf v1.26.0 // indirect
+	gopkg.in/airbrake/gobrake.v2 v2.0.9 // indirect
+	gopkg.in/gemnasium/logrus-airbrake-hook.v2 v2.1.2 // indirect
+)
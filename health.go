@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/Sirupsen/logrus"
+	"github.com/garyburd/redigo/redis"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	HealthPath                = "/healthz"
+	ReadinessPath             = "/readyz"
+	HealthStatusOK            = "ok"
+	HealthStatusFailing       = "failing"
+	HealthCheckTimeout        = 5
+	HealthDiscordHeartbeatAge = 120
+	HealthEVEStaleIntervals   = 3
+)
+
+type HealthStatus struct {
+	mutex               sync.RWMutex
+	lastStarbaseUpdate  time.Time
+	lastStarbaseFailure time.Time
+}
+
+type ComponentStatus struct {
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+type ReadinessStatus struct {
+	Status     string                      `json:"status"`
+	Components map[string]*ComponentStatus `json:"components"`
+}
+
+func (h *HealthStatus) recordStarbaseUpdate(success bool) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if success {
+		h.lastStarbaseUpdate = time.Now().UTC()
+	} else {
+		h.lastStarbaseFailure = time.Now().UTC()
+	}
+}
+
+func (h *HealthStatus) lastStarbaseUpdates() (time.Time, time.Time) {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	return h.lastStarbaseUpdate, h.lastStarbaseFailure
+}
+
+func newComponentStatus(message string, failing bool) *ComponentStatus {
+	if failing {
+		return &ComponentStatus{Status: HealthStatusFailing, Message: message}
+	}
+
+	return &ComponentStatus{Status: HealthStatusOK, Message: message}
+}
+
+func (b *Bot) checkDiscordReadiness() *ComponentStatus {
+	b.discord.RLock()
+	ready := b.discord.DataReady
+	lastHeartbeatAck := b.discord.LastHeartbeatAck
+	b.discord.RUnlock()
+
+	if !ready {
+		return newComponentStatus("Gateway connection not ready", true)
+	}
+
+	if time.Since(lastHeartbeatAck) > time.Second*HealthDiscordHeartbeatAge {
+		return newComponentStatus(fmt.Sprintf("No heartbeat acknowledged since %s", lastHeartbeatAck.UTC().Format(time.RFC3339)), true)
+	}
+
+	return newComponentStatus(fmt.Sprintf("Heartbeat latency %v", b.discord.HeartbeatLatency().Round(time.Millisecond)), false)
+}
+
+func (b *Bot) checkRedisReadiness() *ComponentStatus {
+	r := b.redis.Get()
+	defer r.Close()
+
+	_, err := redis.DoWithTimeout(r, time.Second*HealthCheckTimeout, "PING")
+	if err != nil {
+		log.WithError(err).Warn("Redis readiness check failed")
+		return newComponentStatus("Failed to ping Redis server", true)
+	}
+
+	return newComponentStatus(fmt.Sprintf("%d active connections", b.redis.ActiveCount()), false)
+}
+
+func (b *Bot) checkMySQLReadiness(ctx context.Context) *ComponentStatus {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*HealthCheckTimeout)
+	defer cancel()
+
+	if err := b.mysql.PingContext(ctx); err != nil {
+		log.WithError(err).Warn("MySQL readiness check failed")
+		return newComponentStatus("Failed to ping MySQL server", true)
+	}
+
+	return newComponentStatus("", false)
+}
+
+func (b *Bot) checkEVEReadiness() *ComponentStatus {
+	lastUpdate, lastFailure := b.health.lastStarbaseUpdates()
+	if lastUpdate.IsZero() {
+		if !lastFailure.IsZero() {
+			return newComponentStatus("Starbase details have not been updated successfully yet", true)
+		}
+		return newComponentStatus("Waiting for first starbase update", true)
+	}
+
+	maxAge := time.Second * time.Duration(b.config.EVE.MonitorInterval*HealthEVEStaleIntervals)
+	age := time.Since(lastUpdate)
+	if age > maxAge {
+		return newComponentStatus(fmt.Sprintf("Starbase details last updated %v ago", age.Round(time.Second)), true)
+	}
+
+	return newComponentStatus(fmt.Sprintf("Starbase details updated %v ago", age.Round(time.Second)), false)
+}
+
+func (b *Bot) handleHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeAPIError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	writeAPIJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  HealthStatusOK,
+		"version": Version,
+		"uptime":  time.Since(b.startTime).Round(time.Second).String(),
+	})
+}
+
+func (b *Bot) handleReadiness(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeAPIError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	readiness := &ReadinessStatus{
+		Status: HealthStatusOK,
+		Components: map[string]*ComponentStatus{
+			"discord": b.checkDiscordReadiness(),
+			"redis":   b.checkRedisReadiness(),
+			"mysql":   b.checkMySQLReadiness(r.Context()),
+			"eve":     b.checkEVEReadiness(),
+		},
+	}
+
+	status := http.StatusOK
+	failing := make([]string, 0)
+	for name, component := range readiness.Components {
+		if component.Status != HealthStatusOK {
+			failing = append(failing, name)
+		}
+	}
+
+	if len(failing) > 0 {
+		readiness.Status = HealthStatusFailing
+		status = http.StatusServiceUnavailable
+		log.WithFields(logrus.Fields{
+			"remote":  r.RemoteAddr,
+			"failing": failing,
+		}).Warn("Readiness check failed")
+	}
+
+	writeAPIJSON(w, status, readiness)
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+	"github.com/garyburd/redigo/redis"
+	"strconv"
+	"strings"
+)
+
+const (
+	DiscordSlashCommandName      = "pos"
+	DiscordAutocompleteMaxChoice = 25
+	DiscordChoiceNameMaxLength   = 100
+)
+
+func discordApplicationCommands(registry *CommandRegistry) []*discordgo.ApplicationCommand {
+	minValue := 1.0
+
+	subCommands := make([]*discordgo.ApplicationCommandOption, 0)
+	for _, command := range registry.Commands() {
+		options := make([]*discordgo.ApplicationCommandOption, 0)
+		for _, arg := range command.Arguments {
+			option := &discordgo.ApplicationCommandOption{
+				Name:        arg.Name,
+				Description: arg.Description,
+				Required:    arg.Required,
+			}
+
+			switch arg.Type {
+			case CommandArgumentStarbase:
+				option.Type = discordgo.ApplicationCommandOptionInteger
+				option.Autocomplete = true
+			case CommandArgumentDays, CommandArgumentHours:
+				option.Type = discordgo.ApplicationCommandOptionInteger
+				option.MinValue = &minValue
+			case CommandArgumentFlag:
+				option.Type = discordgo.ApplicationCommandOptionBoolean
+			default:
+				option.Type = discordgo.ApplicationCommandOptionString
+			}
+
+			for _, choice := range arg.Choices {
+				option.Choices = append(option.Choices, &discordgo.ApplicationCommandOptionChoice{
+					Name:  choice,
+					Value: choice,
+				})
+			}
+
+			options = append(options, option)
+		}
+
+		subCommands = append(subCommands, &discordgo.ApplicationCommandOption{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        command.Name,
+			Description: truncateString(command.Description, DiscordChoiceNameMaxLength),
+			Options:     options,
+		})
+	}
+
+	return []*discordgo.ApplicationCommand{
+		{
+			Name:        DiscordSlashCommandName,
+			Description: "Keep track of your EVE Online POSes",
+			Options:     subCommands,
+		},
+	}
+}
+
+func (b *Bot) registerDiscordApplicationCommands(s *discordgo.Session) {
+	commands, err := s.ApplicationCommandBulkOverwrite(s.State.User.ID, b.config.Discord.GuildID, discordApplicationCommands(b.commands))
+	if err != nil {
+		log.WithField("guildID", b.config.Discord.GuildID).WithError(err).Error("Failed to register Discord application commands")
+		return
+	}
+
+	log.WithField("count", len(commands)).Info("Registered Discord application commands")
+}
+
+func (b *Bot) onDiscordInteractionCreate(s *discordgo.Session, interaction *discordgo.InteractionCreate) {
+	if interaction.Type != discordgo.InteractionApplicationCommand && interaction.Type != discordgo.InteractionApplicationCommandAutocomplete {
+		return
+	}
+
+	data := interaction.ApplicationCommandData()
+	if data.Name != DiscordSlashCommandName || len(data.Options) == 0 {
+		return
+	}
+
+	if interaction.Type == discordgo.InteractionApplicationCommandAutocomplete {
+		b.respondDiscordStarbaseAutocomplete(interaction, data.Options[0].Options)
+		return
+	}
+
+	if !strings.EqualFold(interaction.GuildID, b.config.Discord.GuildID) || !strings.EqualFold(interaction.ChannelID, b.config.Discord.ChannelID) {
+		b.respondDiscordInteraction(interaction, fmt.Sprintf("I'm only listening in <#%s>, come find me there :wave:", b.config.Discord.ChannelID), true)
+		return
+	}
+
+	if interaction.Member == nil || interaction.Member.User == nil {
+		return
+	}
+
+	b.handleDiscordPOSInteraction(interaction, data.Options[0])
+}
+
+func (b *Bot) handleDiscordPOSInteraction(interaction *discordgo.InteractionCreate, subCommand *discordgo.ApplicationCommandInteractionDataOption) {
+	command := b.commands.Find(subCommand.Name)
+	if command == nil {
+		b.respondDiscordInteraction(interaction, "I don't know this command (yet) :thinking:", true)
+		return
+	}
+
+	user := interaction.Member.User
+	acknowledged := false
+	ctx := &CommandContext{
+		ChannelID: interaction.ChannelID,
+		UserID:    user.ID,
+		UserName:  user.Username,
+		IsAdmin:   b.isBotAdminMember(interaction.Member),
+		Reply: func(content string) {
+			if acknowledged {
+				b.followupDiscordInteraction(interaction, content, true)
+				return
+			}
+			b.respondDiscordInteraction(interaction, content, true)
+		},
+		Fail: func(content string) {
+			if acknowledged {
+				b.followupDiscordInteraction(interaction, content, true)
+				return
+			}
+			b.respondDiscordInteraction(interaction, content, true)
+		},
+		Acknowledge: func() {
+			acknowledged = true
+			b.respondDiscordInteraction(interaction, "On it :thumbsup:", true)
+		},
+	}
+
+	var argErr error
+	args := make(map[string]interface{})
+	for _, option := range subCommand.Options {
+		arg := command.Argument(option.Name)
+		if arg == nil {
+			continue
+		}
+
+		raw := fmt.Sprintf("%v", option.Value)
+		if option.Type == discordgo.ApplicationCommandOptionInteger {
+			raw = strconv.FormatInt(option.IntValue(), 10)
+		}
+
+		value, err := arg.parse(raw)
+		if err != nil {
+			argErr = &CommandArgumentError{Argument: arg, Value: raw}
+			break
+		}
+		args[arg.Name] = value
+	}
+
+	b.executeCommand(command, ctx, args, argErr)
+}
+
+func (b *Bot) respondDiscordStarbaseAutocomplete(interaction *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	query := ""
+	for _, option := range options {
+		if option.Focused {
+			query = strings.ToLower(fmt.Sprintf("%v", option.Value))
+			break
+		}
+	}
+
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0)
+
+	// autocomplete has to respond within 3 seconds, so only data already cached in redis is used here
+	starbases, err := b.retrieveCachedStarbaseList()
+	if err == redis.ErrNil {
+		starbases, err = b.retrieveStarbaseSnapshot()
+	}
+	if err != nil && err != redis.ErrNil {
+		log.WithError(err).Warn("Failed to retrieve cached starbase list for Discord autocomplete")
+	}
+
+	if starbases != nil {
+		for _, starbase := range starbases.Starbases {
+			if len(choices) >= DiscordAutocompleteMaxChoice {
+				break
+			}
+			if !b.isStarbaseMonitored(starbase.ID) {
+				continue
+			}
+
+			id := strconv.Itoa(starbase.ID)
+			locationName := fmt.Sprintf("POS #%s", id)
+			if pos, err := b.retrieveCachedPOS(starbase.ID); err == nil && pos != nil {
+				locationName = pos.LocationName
+			}
+
+			if len(query) > 0 && !strings.Contains(id, query) && !strings.Contains(strings.ToLower(locationName), query) {
+				continue
+			}
+
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+				Name:  truncateString(fmt.Sprintf("%s (%s)", locationName, id), DiscordChoiceNameMaxLength),
+				Value: starbase.ID,
+			})
+		}
+	}
+
+	err = b.discord.InteractionRespond(interaction.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{
+			Choices: choices,
+		},
+	})
+	if err != nil {
+		log.WithError(err).Warn("Failed to respond to Discord autocomplete interaction")
+	}
+}
+
+func (b *Bot) respondDiscordInteraction(interaction *discordgo.InteractionCreate, content string, ephemeral bool) {
+	data := &discordgo.InteractionResponseData{
+		Content: content,
+	}
+	if ephemeral {
+		data.Flags = discordgo.MessageFlagsEphemeral
+	}
+
+	err := b.discord.InteractionRespond(interaction.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: data,
+	})
+	if err != nil {
+		log.WithField("interactionID", interaction.ID).WithError(err).Warn("Failed to respond to Discord interaction")
+	}
+}
+
+func (b *Bot) followupDiscordInteraction(interaction *discordgo.InteractionCreate, content string, ephemeral bool) {
+	params := &discordgo.WebhookParams{
+		Content: content,
+	}
+	if ephemeral {
+		params.Flags = discordgo.MessageFlagsEphemeral
+	}
+
+	_, err := b.discord.FollowupMessageCreate(interaction.Interaction, false, params)
+	if err != nil {
+		log.WithField("interactionID", interaction.ID).WithError(err).Warn("Failed to send Discord interaction follow-up")
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"github.com/Sirupsen/logrus"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	MatrixMessageTypeText   = "m.text"
+	MatrixMessageTypeNotice = "m.notice"
+	MatrixMessageFormatHTML = "org.matrix.custom.html"
+)
+
+type MatrixRoute struct {
+	RoomID         string   `json:"roomID"`
+	Severity       string   `json:"severity"`
+	MentionRoom    bool     `json:"mentionRoom"`
+	MentionUserIDs []string `json:"mentionUserIDs"`
+}
+
+type MatrixNotifier struct {
+	BaseURL     string
+	AccessToken string
+	Routes      []MatrixRoute
+	client      *http.Client
+	transaction int64
+}
+
+type matrixMentions struct {
+	UserIDs []string `json:"user_ids,omitempty"`
+	Room    bool     `json:"room,omitempty"`
+}
+
+type matrixMessage struct {
+	MessageType   string          `json:"msgtype"`
+	Body          string          `json:"body"`
+	Format        string          `json:"format"`
+	FormattedBody string          `json:"formatted_body"`
+	Mentions      *matrixMentions `json:"m.mentions"`
+}
+
+func NewMatrixNotifier(baseURL string, accessToken string, routes []MatrixRoute, client *http.Client) *MatrixNotifier {
+	return &MatrixNotifier{
+		BaseURL:     strings.TrimRight(baseURL, "/"),
+		AccessToken: accessToken,
+		Routes:      routes,
+		client:      client,
+	}
+}
+
+func (n *MatrixNotifier) Name() string {
+	return "matrix"
+}
+
+func (n *MatrixNotifier) Notify(alerts []*FuelAlert) error {
+	var lastErr error
+	for _, route := range n.Routes {
+		filtered := filterFuelAlerts(alerts, route.Severity)
+		if len(filtered) == 0 {
+			continue
+		}
+
+		if err := n.send(route, filtered); err != nil {
+			log.WithField("roomID", route.RoomID).WithError(err).Warn("Failed to send Matrix message")
+			lastErr = err
+			continue
+		}
+
+		recordNotificationsSent(n.Name(), filtered)
+		log.WithFields(logrus.Fields{
+			"roomID": route.RoomID,
+			"count":  len(filtered),
+		}).Info("Matrix fuel alert message sent")
+	}
+
+	return lastErr
+}
+
+func (n *MatrixNotifier) send(route MatrixRoute, alerts []*FuelAlert) error {
+	critical := countCriticalFuelAlerts(alerts)
+
+	message := &matrixMessage{
+		MessageType: MatrixMessageTypeNotice,
+		Format:      MatrixMessageFormatHTML,
+		Mentions:    &matrixMentions{},
+	}
+
+	header := fmt.Sprintf("%d POS fuel alert(s)", len(alerts))
+	if critical > 0 {
+		message.MessageType = MatrixMessageTypeText
+		header = fmt.Sprintf("%d POS fuel alert(s), %d critical", len(alerts), critical)
+
+		mentions := make([]string, 0)
+		if route.MentionRoom {
+			mentions = append(mentions, "@room")
+			message.Mentions.Room = true
+		}
+		for _, userID := range route.MentionUserIDs {
+			mentions = append(mentions, userID)
+			message.Mentions.UserIDs = append(message.Mentions.UserIDs, userID)
+		}
+		if len(mentions) > 0 {
+			header = fmt.Sprintf("%s %s", strings.Join(mentions, " "), header)
+		}
+	}
+
+	lines := []string{header}
+	htmlLines := []string{fmt.Sprintf("<b>%s</b>", header)}
+	for _, alert := range alerts {
+		lines = append(lines, fmt.Sprintf("- %s", formatFuelAlertText(alert)))
+		htmlLines = append(htmlLines, fmt.Sprintf("<li>%s</li>", formatFuelAlertHTML(alert)))
+	}
+	message.Body = strings.Join(lines, "\n")
+	message.FormattedBody = fmt.Sprintf("%s<ul>%s</ul>", htmlLines[0], strings.Join(htmlLines[1:], ""))
+
+	n.transaction++
+	endpoint := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/posbot-%d-%d", n.BaseURL, url.PathEscape(route.RoomID), time.Now().UnixNano(), n.transaction)
+
+	return sendNotifierRequest(n.client, http.MethodPut, endpoint, map[string]string{
+		"Authorization": fmt.Sprintf("Bearer %s", n.AccessToken),
+	}, message)
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type matrixTestRequest struct {
+	Method        string
+	Path          string
+	Authorization string
+	Message       *matrixMessage
+	Mentions      map[string]interface{}
+}
+
+type matrixTestServer struct {
+	*httptest.Server
+	requests []*matrixTestRequest
+}
+
+func newMatrixTestServer(t *testing.T) *matrixTestServer {
+	server := &matrixTestServer{}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("Failed to read Matrix message: %v", err)
+		}
+
+		message := &matrixMessage{}
+		if err = json.Unmarshal(data, message); err != nil {
+			t.Errorf("Failed to parse Matrix message: %v", err)
+		}
+		// decoded separately to verify which fields actually end up in m.mentions
+		var raw struct {
+			Mentions map[string]interface{} `json:"m.mentions"`
+		}
+		if err = json.Unmarshal(data, &raw); err != nil {
+			t.Errorf("Failed to parse Matrix mentions: %v", err)
+		}
+
+		server.requests = append(server.requests, &matrixTestRequest{
+			Method:        r.Method,
+			Path:          r.URL.EscapedPath(),
+			Authorization: r.Header.Get("Authorization"),
+			Message:       message,
+			Mentions:      raw.Mentions,
+		})
+
+		w.Write([]byte(`{"event_id":"$event"}`))
+	}))
+
+	return server
+}
+
+func TestMatrixNotifierNotify(t *testing.T) {
+	server := newMatrixTestServer(t)
+	defer server.Close()
+
+	notifier := NewMatrixNotifier(server.URL+"/", "access-token", []MatrixRoute{
+		{RoomID: "!fuel/techs:example.org", Severity: AlertSeverityWarning, MentionRoom: true, MentionUserIDs: []string{"@pilot:example.org"}},
+	}, server.Client())
+
+	alerts := []*FuelAlert{
+		newTestFuelAlert(1, "Moon <1>", AlertSeverityWarning),
+		newTestFuelAlert(2, "Moon 2", AlertSeverityCritical),
+	}
+	if err := notifier.Notify(alerts); err != nil {
+		t.Fatalf("Notify returned error: %v", err)
+	}
+
+	if len(server.requests) != 1 {
+		t.Fatalf("Expected 1 request, got %d", len(server.requests))
+	}
+
+	request := server.requests[0]
+	if request.Method != http.MethodPut {
+		t.Errorf("Unexpected method %q", request.Method)
+	}
+	if !strings.HasPrefix(request.Path, "/_matrix/client/v3/rooms/%21fuel%2Ftechs:example.org/send/m.room.message/posbot-") {
+		t.Errorf("Expected escaped room ID in path, got %q", request.Path)
+	}
+	if request.Authorization != "Bearer access-token" {
+		t.Errorf("Unexpected authorization header %q", request.Authorization)
+	}
+
+	message := request.Message
+	if message.MessageType != MatrixMessageTypeText {
+		t.Errorf("Expected %q for critical alerts, got %q", MatrixMessageTypeText, message.MessageType)
+	}
+	if message.Format != MatrixMessageFormatHTML {
+		t.Errorf("Unexpected format %q", message.Format)
+	}
+	if !strings.HasPrefix(message.Body, "@room @pilot:example.org 2 POS fuel alert(s), 1 critical") {
+		t.Errorf("Unexpected body %q", message.Body)
+	}
+	if !strings.Contains(message.FormattedBody, "<li><b>Moon &lt;1&gt;</b>") {
+		t.Errorf("Expected escaped location in formatted body %q", message.FormattedBody)
+	}
+	if room, _ := request.Mentions["room"].(bool); !room {
+		t.Errorf("Expected room mention, got %v", request.Mentions)
+	}
+	if userIDs, _ := request.Mentions["user_ids"].([]interface{}); len(userIDs) != 1 || userIDs[0] != "@pilot:example.org" {
+		t.Errorf("Expected user mention, got %v", request.Mentions)
+	}
+}
+
+func TestMatrixNotifierNotifyWarningsNotice(t *testing.T) {
+	server := newMatrixTestServer(t)
+	defer server.Close()
+
+	notifier := NewMatrixNotifier(server.URL, "access-token", []MatrixRoute{
+		{RoomID: "!warnings:example.org", MentionRoom: true},
+		{RoomID: "!critical:example.org", Severity: AlertSeverityCritical, MentionRoom: true},
+	}, server.Client())
+	if err := notifier.Notify([]*FuelAlert{newTestFuelAlert(1, "Moon 1", AlertSeverityWarning)}); err != nil {
+		t.Fatalf("Notify returned error: %v", err)
+	}
+
+	if len(server.requests) != 1 {
+		t.Fatalf("Expected 1 request for warnings only, got %d", len(server.requests))
+	}
+
+	request := server.requests[0]
+	if !strings.Contains(request.Path, "/rooms/%21warnings:example.org/") {
+		t.Errorf("Expected message for warnings room, got %q", request.Path)
+	}
+	if request.Message.MessageType != MatrixMessageTypeNotice {
+		t.Errorf("Expected %q for warnings, got %q", MatrixMessageTypeNotice, request.Message.MessageType)
+	}
+	if strings.Contains(request.Message.Body, "@room") || len(request.Mentions) != 0 {
+		t.Errorf("Expected no mentions for warnings, got %q and %v", request.Message.Body, request.Mentions)
+	}
+}
+
+func TestMatrixNotifierNotifyTransactions(t *testing.T) {
+	server := newMatrixTestServer(t)
+	defer server.Close()
+
+	notifier := NewMatrixNotifier(server.URL, "access-token", []MatrixRoute{{RoomID: "!room:example.org"}}, server.Client())
+	for i := 0; i < 2; i++ {
+		if err := notifier.Notify([]*FuelAlert{newTestFuelAlert(1, "Moon 1", AlertSeverityWarning)}); err != nil {
+			t.Fatalf("Notify returned error: %v", err)
+		}
+	}
+
+	if len(server.requests) != 2 || server.requests[0].Path == server.requests[1].Path {
+		t.Errorf("Expected unique transaction IDs, got %d requests", len(server.requests))
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	MetricsNamespace       = "posbot"
+	MetricsPath            = "/metrics"
+	MetricsCacheHit        = "hit"
+	MetricsCacheMiss       = "miss"
+	MetricsCacheStarbases  = "starbase_list"
+	MetricsCacheDetails    = "starbase_details"
+	MetricsCachePOS        = "pos"
+	MetricsCacheFuelPrice  = "fuel_price"
+	MetricsFailureUpdate   = "update"
+	MetricsFailureList     = "list"
+	MetricsFailureStarbase = "starbase"
+)
+
+var (
+	metricStarbaseFuelQuantity = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: MetricsNamespace,
+		Name:      "starbase_fuel_quantity",
+		Help:      "Quantity of fuel currently stored in a monitored POS.",
+	}, []string{"starbase_id", "location", "fuel"})
+	metricStarbaseFuelHoursRemaining = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: MetricsNamespace,
+		Name:      "starbase_fuel_hours_remaining",
+		Help:      "Hours until a monitored POS runs out of the given fuel.",
+	}, []string{"starbase_id", "location", "fuel"})
+	metricStarbaseState = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: MetricsNamespace,
+		Name:      "starbase_state",
+		Help:      "Current state of a monitored POS, set to 1 for the active state.",
+	}, []string{"starbase_id", "location", "state"})
+	metricFuelCheckDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: MetricsNamespace,
+		Name:      "fuel_check_duration_seconds",
+		Help:      "Duration of the periodic starbase fuel check.",
+		Buckets:   prometheus.ExponentialBuckets(0.1, 2, 12),
+	})
+	metricFuelCheckFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: MetricsNamespace,
+		Name:      "fuel_check_failures_total",
+		Help:      "Number of failures encountered during fuel checks.",
+	}, []string{"reason"})
+	metricEVEAPIDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: MetricsNamespace,
+		Name:      "eve_api_request_duration_seconds",
+		Help:      "Latency of requests to the EVE API and ESI.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"endpoint"})
+	metricEVEAPIErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: MetricsNamespace,
+		Name:      "eve_api_request_errors_total",
+		Help:      "Number of failed requests to the EVE API and ESI.",
+	}, []string{"endpoint"})
+	metricCacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: MetricsNamespace,
+		Name:      "cache_lookups_total",
+		Help:      "Number of redis cache lookups by cache and result.",
+	}, []string{"cache", "result"})
+	metricNotificationsSent = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: MetricsNamespace,
+		Name:      "notifications_sent_total",
+		Help:      "Number of fuel alerts delivered by notifier and severity.",
+	}, []string{"notifier", "severity"})
+	metricNotificationFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: MetricsNamespace,
+		Name:      "notification_failures_total",
+		Help:      "Number of failed notifier deliveries.",
+	}, []string{"notifier"})
+	metricCommandUsage = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: MetricsNamespace,
+		Name:      "command_usage_total",
+		Help:      "Number of times a Discord command was used.",
+	}, []string{"command"})
+	metricCommandErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: MetricsNamespace,
+		Name:      "command_errors_total",
+		Help:      "Number of errors while handling a Discord command.",
+	}, []string{"command"})
+)
+
+func init() {
+	prometheus.MustRegister(
+		metricStarbaseFuelQuantity,
+		metricStarbaseFuelHoursRemaining,
+		metricStarbaseState,
+		metricFuelCheckDuration,
+		metricFuelCheckFailures,
+		metricEVEAPIDuration,
+		metricEVEAPIErrors,
+		metricCacheLookups,
+		metricNotificationsSent,
+		metricNotificationFailures,
+		metricCommandUsage,
+		metricCommandErrors,
+	)
+}
+
+func newMetricsHandler() http.HandlerFunc {
+	return promhttp.Handler().ServeHTTP
+}
+
+func observeEVEAPIRequest(endpoint string, start time.Time, err error) {
+	metricEVEAPIDuration.WithLabelValues(endpoint).Observe(time.Since(start).Seconds())
+	if err != nil {
+		metricEVEAPIErrors.WithLabelValues(endpoint).Inc()
+	}
+}
+
+func recordCacheLookup(cache string, hit bool) {
+	result := MetricsCacheMiss
+	if hit {
+		result = MetricsCacheHit
+	}
+
+	metricCacheLookups.WithLabelValues(cache, result).Inc()
+}
+
+var (
+	starbaseMetricSeries      = make(map[*prometheus.GaugeVec]map[string][]string)
+	starbaseMetricSeriesMutex sync.Mutex
+)
+
+type StarbaseMetrics struct {
+	series map[*prometheus.GaugeVec]map[string][]string
+}
+
+func newStarbaseMetrics() *StarbaseMetrics {
+	return &StarbaseMetrics{
+		series: make(map[*prometheus.GaugeVec]map[string][]string),
+	}
+}
+
+func (m *StarbaseMetrics) set(gauge *prometheus.GaugeVec, value float64, labels ...string) {
+	gauge.WithLabelValues(labels...).Set(value)
+
+	if _, ok := m.series[gauge]; !ok {
+		m.series[gauge] = make(map[string][]string)
+	}
+	m.series[gauge][strings.Join(labels, "\x00")] = labels
+}
+
+func (m *StarbaseMetrics) Update(pos *POS) {
+	starbaseID := strconv.Itoa(pos.ID)
+
+	m.set(metricStarbaseState, 1, starbaseID, pos.LocationName, pos.State.String())
+	for _, fuel := range pos.Fuel {
+		m.set(metricStarbaseFuelQuantity, float64(fuel.Quantity), starbaseID, pos.LocationName, fuel.TypeName)
+		m.set(metricStarbaseFuelHoursRemaining, fuel.HoursRemaining, starbaseID, pos.LocationName, fuel.TypeName)
+	}
+}
+
+func (m *StarbaseMetrics) Finish() {
+	starbaseMetricSeriesMutex.Lock()
+	defer starbaseMetricSeriesMutex.Unlock()
+
+	// series are only removed once the check is done, so scrapes never see a partially refilled set of POSes
+	for gauge, series := range starbaseMetricSeries {
+		for key, labels := range series {
+			if _, ok := m.series[gauge][key]; !ok {
+				gauge.DeleteLabelValues(labels...)
+			}
+		}
+	}
+
+	starbaseMetricSeries = m.series
+}
+
+func recordNotificationsSent(notifier string, alerts []*FuelAlert) {
+	for _, alert := range alerts {
+		recordNotificationSent(notifier, alert.Severity, 1)
+	}
+}
+
+func recordNotificationSent(notifier string, severity string, count int) {
+	metricNotificationsSent.WithLabelValues(notifier, severity).Add(float64(count))
+}
@@ -24,3 +24,27 @@ func (b *Bot) getLocationNameFromMoonID(moonID int) (string, error) {
 	}).Debug("Retrieved location name for moon ID from MySQL")
 	return name, nil
 }
+
+type MoonLocation struct {
+	SolarSystemID   int    `db:"solarSystemID"`
+	SolarSystemName string `db:"solarSystemName"`
+	RegionID        int    `db:"regionID"`
+	RegionName      string `db:"regionName"`
+}
+
+func (b *Bot) getMoonLocationFromMoonID(moonID int) (*MoonLocation, error) {
+	log.WithField("moonID", moonID).Debug("Retrieving moon location for moon ID from MySQL")
+
+	location := &MoonLocation{}
+	err := b.mysql.Get(location, "SELECT m.solarSystemID, s.itemName AS solarSystemName, m.regionID, r.itemName AS regionName FROM mapDenormalize m JOIN mapDenormalize s ON s.itemID = m.solarSystemID JOIN mapDenormalize r ON r.itemID = m.regionID WHERE m.itemID = ?", moonID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to query moon location")
+	}
+
+	log.WithFields(logrus.Fields{
+		"moonID":          moonID,
+		"solarSystemName": location.SolarSystemName,
+		"regionName":      location.RegionName,
+	}).Debug("Retrieved moon location for moon ID from MySQL")
+	return location, nil
+}
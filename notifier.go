@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/Sirupsen/logrus"
+	"github.com/pkg/errors"
+	"html"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	AlertSeverityWarning  = "warning"
+	AlertSeverityCritical = "critical"
+	NotifierTimeout       = 10
+	DiscordNotifierName   = "discord"
+)
+
+type Notifier interface {
+	Name() string
+	Notify(alerts []*FuelAlert) error
+}
+
+type DiscordNotifier struct {
+	bot *Bot
+}
+
+func (n *DiscordNotifier) Name() string {
+	return DiscordNotifierName
+}
+
+func (n *DiscordNotifier) Notify(alerts []*FuelAlert) error {
+	n.bot.sendDiscordFuelAlerts(alerts)
+	return nil
+}
+
+func (b *Bot) newNotifiers() []Notifier {
+	client := &http.Client{
+		Timeout: time.Second * NotifierTimeout,
+	}
+
+	notifiers := []Notifier{&DiscordNotifier{bot: b}}
+	if b.config.Notifiers.Slack.Enabled {
+		notifiers = append(notifiers, NewSlackNotifier(b.config.Notifiers.Slack.WebhookURL, b.config.Notifiers.Slack.Severity, client))
+	}
+	if b.config.Notifiers.Webhook.Enabled {
+		b.webhook = NewWebhookNotifier(b.config.Notifiers.Webhook.URLs, b.config.Notifiers.Webhook.Secret, b.config.Notifiers.Webhook.MaxAttempts, time.Second*time.Duration(b.config.Notifiers.Webhook.Backoff), client, b.storeWebhookDeadLetter)
+		notifiers = append(notifiers, b.webhook)
+	}
+	if b.config.Notifiers.Email.Enabled {
+		b.email = NewEmailNotifier(b.config.Notifiers.Email.Address, b.config.Notifiers.Email.Username, b.config.Notifiers.Email.Password, b.config.Notifiers.Email.From, b.config.Notifiers.Email.Security, b.config.Notifiers.Email.Recipients)
+		notifiers = append(notifiers, b.email)
+	}
+	if b.config.Notifiers.Telegram.Enabled {
+		notifiers = append(notifiers, NewTelegramNotifier(b.config.Notifiers.Telegram.BaseURL, b.config.Notifiers.Telegram.Token, b.config.Notifiers.Telegram.Routes, client))
+	}
+	if b.config.Notifiers.Matrix.Enabled {
+		notifiers = append(notifiers, NewMatrixNotifier(b.config.Notifiers.Matrix.BaseURL, b.config.Notifiers.Matrix.AccessToken, b.config.Notifiers.Matrix.Routes, client))
+	}
+
+	return notifiers
+}
+
+func (b *Bot) getAlertSeverity(stageIndex int) string {
+	if stageIndex == len(b.config.Discord.Escalation) {
+		return AlertSeverityCritical
+	}
+
+	return AlertSeverityWarning
+}
+
+func filterFuelAlerts(alerts []*FuelAlert, severity string) []*FuelAlert {
+	if !strings.EqualFold(severity, AlertSeverityCritical) {
+		return alerts
+	}
+
+	filtered := make([]*FuelAlert, 0)
+	for _, alert := range alerts {
+		if alert.Severity == AlertSeverityCritical {
+			filtered = append(filtered, alert)
+		}
+	}
+
+	return filtered
+}
+
+func (b *Bot) sendFuelAlerts(alerts []*FuelAlert) {
+	if len(alerts) == 0 {
+		return
+	}
+
+	if err := b.recordAlertHistory(alerts); err != nil {
+		log.WithField("count", len(alerts)).WithError(err).Warn("Failed to record alert history")
+	}
+
+	for _, notifier := range b.notifiers {
+		if err := notifier.Notify(alerts); err != nil {
+			metricNotificationFailures.WithLabelValues(notifier.Name()).Inc()
+			log.WithFields(logrus.Fields{
+				"notifier": notifier.Name(),
+				"count":    len(alerts),
+			}).WithError(err).Warn("Failed to send fuel alerts")
+			continue
+		}
+	}
+}
+
+func formatFuelAlertText(alert *FuelAlert) string {
+	return fmt.Sprintf("%s (owned by %s): %s of %s left (%s, POS ID %d)", alert.POS.LocationName, alert.POS.OwnerName, alert.Remaining, alert.Fuel.TypeName, alert.Severity, alert.POS.ID)
+}
+
+func formatFuelAlertHTML(alert *FuelAlert) string {
+	return fmt.Sprintf("<b>%s</b> (owned by %s): <b>%s</b> of %s left (%s, POS ID %d)", html.EscapeString(alert.POS.LocationName), html.EscapeString(alert.POS.OwnerName), html.EscapeString(alert.Remaining), html.EscapeString(alert.Fuel.TypeName), alert.Severity, alert.POS.ID)
+}
+
+func countCriticalFuelAlerts(alerts []*FuelAlert) int {
+	critical := 0
+	for _, alert := range alerts {
+		if alert.Severity == AlertSeverityCritical {
+			critical++
+		}
+	}
+
+	return critical
+}
+
+func sendNotifierRequest(client *http.Client, method string, endpoint string, headers map[string]string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return errors.Wrap(err, "Failed to marshal notifier payload to JSON")
+	}
+
+	req, err := http.NewRequest(method, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return errors.Wrap(redactNotifierURLError(err), "Failed to create notifier request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", UserAgent)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.Wrap(redactNotifierURLError(err), "Failed to send notifier request")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return errors.Errorf("Notifier request returned status %d: %s", resp.StatusCode, truncateString(string(body), 200))
+	}
+
+	return nil
+}
+
+func redactNotifierURLError(err error) error {
+	// notifier URLs may contain credentials (Telegram bot token, Slack webhook secret), keep them out of the logs
+	if urlErr, ok := err.(*url.Error); ok {
+		return errors.Wrapf(urlErr.Err, "%s request failed", urlErr.Op)
+	}
+
+	return err
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"github.com/Sirupsen/logrus"
+	"github.com/bwmarrin/discordgo"
+	"github.com/garyburd/redigo/redis"
+	"github.com/pkg/errors"
+	"strings"
+	"time"
+)
+
+const (
+	DiscordEmojiPreviousPage = "⬅️"
+	DiscordEmojiNextPage     = "➡️"
+
+	DiscordMessageMaxEmbeds = 10
+	DefaultPageSize         = 5
+	DefaultPageTimeout      = 900
+)
+
+type PaginatedMessage struct {
+	ChannelID string                      `json:"channelID"`
+	Page      int                         `json:"page"`
+	Pages     [][]*discordgo.MessageEmbed `json:"pages"`
+	Expires   time.Time                   `json:"expires"`
+}
+
+func (p *PaginatedMessage) Content() string {
+	return fmt.Sprintf("Page **%d/%d** - use %s and %s to flip through the pages", p.Page+1, len(p.Pages), DiscordEmojiPreviousPage, DiscordEmojiNextPage)
+}
+
+func (b *Bot) sendDiscordPaginatedEmbeds(channelID string, embeds []*discordgo.MessageEmbed) error {
+	pageSize := b.config.Discord.Pagination.PageSize
+
+	paginated := &PaginatedMessage{
+		ChannelID: channelID,
+		Pages:     make([][]*discordgo.MessageEmbed, 0),
+		Expires:   time.Now().UTC().Add(time.Second * time.Duration(b.config.Discord.Pagination.Timeout)),
+	}
+	for i := 0; i < len(embeds); i += pageSize {
+		end := i + pageSize
+		if end > len(embeds) {
+			end = len(embeds)
+		}
+		paginated.Pages = append(paginated.Pages, embeds[i:end])
+	}
+
+	if len(paginated.Pages) == 0 {
+		return nil
+	} else if len(paginated.Pages) == 1 {
+		_, err := b.discord.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
+			Embeds: paginated.Pages[0],
+		})
+		return err
+	}
+
+	message, err := b.discord.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
+		Content: paginated.Content(),
+		Embeds:  paginated.Pages[0],
+	})
+	if err != nil {
+		return errors.Wrap(err, "Failed to send paginated message")
+	}
+
+	err = b.storePaginatedMessage(message.ID, paginated, b.config.Discord.Pagination.Timeout)
+	if err != nil {
+		return errors.Wrap(err, "Failed to store paginated message")
+	}
+
+	b.discord.MessageReactionAdd(channelID, message.ID, DiscordEmojiPreviousPage)
+	b.discord.MessageReactionAdd(channelID, message.ID, DiscordEmojiNextPage)
+
+	return nil
+}
+
+func (b *Bot) flipDiscordPaginatedMessage(event *discordgo.MessageReactionAdd, direction int) {
+	paginated, err := b.retrievePaginatedMessage(event.MessageID)
+	if err == redis.ErrNil {
+		return
+	} else if err != nil {
+		log.WithField("messageID", event.MessageID).WithError(err).Warn("Failed to retrieve paginated message for reaction")
+		return
+	}
+
+	err = b.discord.MessageReactionRemove(event.ChannelID, event.MessageID, event.Emoji.APIName(), event.UserID)
+	if err != nil {
+		log.WithField("messageID", event.MessageID).WithError(err).Debug("Failed to remove page reaction")
+	}
+
+	page := paginated.Page + direction
+	if page < 0 || page >= len(paginated.Pages) {
+		return
+	}
+	paginated.Page = page
+
+	content := paginated.Content()
+	embeds := paginated.Pages[page]
+	_, err = b.discord.ChannelMessageEditComplex(&discordgo.MessageEdit{
+		ID:      event.MessageID,
+		Channel: event.ChannelID,
+		Content: &content,
+		Embeds:  &embeds,
+	})
+	if err != nil {
+		log.WithField("messageID", event.MessageID).WithError(err).Warn("Failed to edit paginated message")
+		return
+	}
+
+	// keep the original expiry, flipping pages must not extend the timeout
+	remaining := int(paginated.Expires.Sub(time.Now().UTC()).Seconds())
+	if paginated.Expires.IsZero() {
+		remaining = b.config.Discord.Pagination.Timeout
+	}
+	if remaining > 0 {
+		err = b.storePaginatedMessage(event.MessageID, paginated, remaining)
+		if err != nil {
+			log.WithField("messageID", event.MessageID).WithError(err).Warn("Failed to store paginated message")
+		}
+	}
+
+	log.WithFields(logrus.Fields{
+		"messageID": event.MessageID,
+		"page":      page,
+	}).Debug("Flipped page of paginated message")
+}
+
+func isDiscordEmoji(name string, emoji string) bool {
+	return strings.TrimSuffix(name, "\ufe0f") == strings.TrimSuffix(emoji, "\ufe0f")
+}
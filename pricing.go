@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"github.com/Sirupsen/logrus"
+	"github.com/dustin/go-humanize"
+	"github.com/garyburd/redigo/redis"
+	"github.com/pkg/errors"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	PriceSourceOrders     = "orders"
+	PriceSourceAverage    = "average"
+	PriceOrderTypeSell    = "sell"
+	FuelCostDaysPerMonth  = 30
+	DefaultPriceRegionID  = 10000002
+	DefaultPriceCacheTime = 3600
+)
+
+type FuelCost struct {
+	Daily   float64
+	Monthly float64
+}
+
+func (c *FuelCost) Add(other *FuelCost) {
+	c.Daily += other.Daily
+	c.Monthly += other.Monthly
+}
+
+func (c *FuelCost) String() string {
+	return fmt.Sprintf("%s ISK/day, %s ISK/month", formatISK(c.Daily), formatISK(c.Monthly))
+}
+
+func (b *Bot) getFuelPrice(typeID int) (float64, error) {
+	price, err := b.retrieveCachedFuelPrice(typeID)
+	if err != nil && err != redis.ErrNil {
+		return 0, errors.Wrap(err, "Failed to retrieve cached fuel price")
+	}
+
+	if err != redis.ErrNil {
+		recordCacheLookup(MetricsCacheFuelPrice, true)
+		return price, nil
+	}
+	recordCacheLookup(MetricsCacheFuelPrice, false)
+
+	if strings.EqualFold(b.config.EVE.Pricing.Source, PriceSourceAverage) {
+		price, err = b.retrieveAverageMarketPrice(typeID)
+	} else {
+		price, err = b.retrieveLowestSellPrice(typeID)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if err = b.cacheFuelPrice(typeID, price, b.config.EVE.Pricing.CacheDuration); err != nil {
+		log.WithField("typeID", typeID).WithError(err).Warn("Failed to cache fuel price")
+	}
+
+	return price, nil
+}
+
+func (b *Bot) retrieveLowestSellPrice(typeID int) (float64, error) {
+	log.WithFields(logrus.Fields{
+		"typeID":     typeID,
+		"regionID":   b.config.EVE.Pricing.RegionID,
+		"locationID": b.config.EVE.Pricing.LocationID,
+	}).Debug("Retrieving lowest sell price from ESI market orders")
+
+	start := time.Now()
+	orders, _, err := b.esi.MarketApi.GetMarketsRegionIdOrders(PriceOrderTypeSell, int32(b.config.EVE.Pricing.RegionID), map[string]interface{}{"typeId": int32(typeID)})
+	observeEVEAPIRequest("GetMarketsRegionIdOrders", start, err)
+	if err != nil {
+		return 0, errors.Wrap(err, "Failed to retrieve market orders")
+	}
+
+	lowest := math.MaxFloat64
+	for _, order := range orders {
+		if order.IsBuyOrder {
+			continue
+		}
+		if b.config.EVE.Pricing.LocationID > 0 && order.LocationId != int64(b.config.EVE.Pricing.LocationID) {
+			continue
+		}
+		if order.Price < lowest {
+			lowest = order.Price
+		}
+	}
+
+	if lowest == math.MaxFloat64 {
+		return 0, errors.Errorf("No sell orders found for type %d", typeID)
+	}
+
+	log.WithFields(logrus.Fields{
+		"typeID": typeID,
+		"price":  lowest,
+	}).Debug("Retrieved lowest sell price from ESI market orders")
+	return lowest, nil
+}
+
+func (b *Bot) retrieveAverageMarketPrice(typeID int) (float64, error) {
+	log.WithField("typeID", typeID).Debug("Retrieving average market price from ESI")
+
+	start := time.Now()
+	prices, _, err := b.esi.MarketApi.GetMarketsPrices(nil)
+	observeEVEAPIRequest("GetMarketsPrices", start, err)
+	if err != nil {
+		return 0, errors.Wrap(err, "Failed to retrieve market prices")
+	}
+
+	for _, price := range prices {
+		if int(price.TypeId) == typeID {
+			log.WithFields(logrus.Fields{
+				"typeID": typeID,
+				"price":  price.AveragePrice,
+			}).Debug("Retrieved average market price from ESI")
+			return price.AveragePrice, nil
+		}
+	}
+
+	return 0, errors.Errorf("No average market price found for type %d", typeID)
+}
+
+func (b *Bot) getPOSFuelCost(pos *POS) (*FuelCost, error) {
+	cost := &FuelCost{}
+	for _, fuel := range pos.Fuel {
+		if !fuel.ConstantlyRequired {
+			continue
+		}
+
+		price, err := b.getFuelPrice(fuel.TypeID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to retrieve price for fuel type %d", fuel.TypeID)
+		}
+
+		cost.Daily += float64(fuel.Required*24) * price
+	}
+	cost.Monthly = cost.Daily * FuelCostDaysPerMonth
+
+	return cost, nil
+}
+
+func formatISK(isk float64) string {
+	return humanize.Comma(int64(math.Ceil(isk)))
+}
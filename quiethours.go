@@ -0,0 +1,331 @@
+package main
+
+import (
+	"fmt"
+	"github.com/Sirupsen/logrus"
+	"github.com/bwmarrin/discordgo"
+	"github.com/garyburd/redigo/redis"
+	"github.com/pkg/errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	QuietHoursModeSilent = "silent"
+	QuietHoursModeQueue  = "queue"
+)
+
+type QuietHours struct {
+	Destination string `json:"destination"`
+	Timezone    string `json:"timezone"`
+	Start       string `json:"start"`
+	End         string `json:"end"`
+	Mode        string `json:"mode"`
+}
+
+type QuietHoursWindow struct {
+	Destination string
+	Location    *time.Location
+	Start       int
+	End         int
+	Mode        string
+}
+
+func parseQuietHours(quietHours QuietHours, defaultChannelID string) (*QuietHoursWindow, error) {
+	window := &QuietHoursWindow{
+		Destination: quietHours.Destination,
+		Mode:        strings.ToLower(quietHours.Mode),
+	}
+	if len(window.Destination) == 0 {
+		window.Destination = defaultChannelID
+	}
+	if len(window.Mode) == 0 {
+		window.Mode = QuietHoursModeSilent
+	}
+	if window.Mode != QuietHoursModeSilent && window.Mode != QuietHoursModeQueue {
+		return nil, errors.Errorf("Quiet hours mode %q is unknown", quietHours.Mode)
+	}
+
+	var err error
+	window.Location, err = time.LoadLocation(quietHours.Timezone)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Quiet hours timezone %q is invalid", quietHours.Timezone)
+	}
+
+	window.Start, err = parseTimeOfDay(quietHours.Start)
+	if err != nil {
+		return nil, errors.Wrap(err, "Quiet hours start is invalid")
+	}
+	window.End, err = parseTimeOfDay(quietHours.End)
+	if err != nil {
+		return nil, errors.Wrap(err, "Quiet hours end is invalid")
+	}
+	if window.Start == window.End {
+		return nil, errors.New("Quiet hours start and end must differ")
+	}
+
+	return window, nil
+}
+
+func parseTimeOfDay(str string) (int, error) {
+	parts := strings.Split(str, ":")
+	if len(parts) != 2 {
+		return 0, errors.Errorf("Time %q must be in the form of \"HH:MM\"", str)
+	}
+
+	hour, err := strconv.Atoi(parts[0])
+	if err != nil || hour < 0 || hour > 23 {
+		return 0, errors.Errorf("Invalid hour in time %q", str)
+	}
+	minute, err := strconv.Atoi(parts[1])
+	if err != nil || minute < 0 || minute > 59 {
+		return 0, errors.Errorf("Invalid minute in time %q", str)
+	}
+
+	return hour*60 + minute, nil
+}
+
+func (w *QuietHoursWindow) Active(now time.Time) bool {
+	local := now.In(w.Location)
+	minutes := local.Hour()*60 + local.Minute()
+
+	if w.Start < w.End {
+		return minutes >= w.Start && minutes < w.End
+	}
+	return minutes >= w.Start || minutes < w.End
+}
+
+func (b *Bot) getActiveQuietHours(destination string, now time.Time) *QuietHoursWindow {
+	for _, window := range b.quietHours {
+		if strings.EqualFold(window.Destination, destination) && window.Active(now) {
+			return window
+		}
+	}
+
+	return nil
+}
+
+type DiscordAlertMessage struct {
+	ChannelID      string                  `json:"channelID,omitempty"`
+	UserID         string                  `json:"userID,omitempty"`
+	Mention        string                  `json:"mention,omitempty"`
+	Content        string                  `json:"content"`
+	Embed          *discordgo.MessageEmbed `json:"embed,omitempty"`
+	StarbaseIDs    []int                   `json:"starbaseIDs"`
+	StageIndex     int                     `json:"stageIndex"`
+	HoursRemaining float64                 `json:"hoursRemaining"`
+	QueuedAt       time.Time               `json:"queuedAt"`
+}
+
+func (m *DiscordAlertMessage) Destination() string {
+	if len(m.UserID) > 0 {
+		return m.UserID
+	}
+	return m.ChannelID
+}
+
+func (m *DiscordAlertMessage) Key() string {
+	ids := make([]string, 0)
+	for _, id := range m.StarbaseIDs {
+		ids = append(ids, strconv.Itoa(id))
+	}
+
+	return fmt.Sprintf("%s:%s", m.Destination(), strings.Join(ids, ","))
+}
+
+func newDiscordAlertMessage(alerts []*FuelAlert) *DiscordAlertMessage {
+	message := &DiscordAlertMessage{
+		StarbaseIDs: make([]int, 0),
+	}
+
+	for i, alert := range alerts {
+		if alert.StageIndex > message.StageIndex {
+			message.StageIndex = alert.StageIndex
+		}
+		if i == 0 || alert.Fuel.HoursRemaining < message.HoursRemaining {
+			message.HoursRemaining = alert.Fuel.HoursRemaining
+		}
+		message.StarbaseIDs = append(message.StarbaseIDs, alert.POS.ID)
+	}
+
+	return message
+}
+
+func (b *Bot) overridesQuietHours(message *DiscordAlertMessage) bool {
+	floor := b.config.Discord.QuietHours.CriticalFloor
+	return floor > 0 && message.StageIndex == len(b.config.Discord.Escalation) && message.HoursRemaining <= float64(floor)
+}
+
+func (b *Bot) deliverDiscordAlert(message *DiscordAlertMessage) error {
+	window := b.getActiveQuietHours(message.Destination(), time.Now().UTC())
+	if window == nil {
+		return b.sendDiscordAlert(message, false)
+	}
+
+	if b.overridesQuietHours(message) {
+		log.WithFields(logrus.Fields{
+			"destination":    message.Destination(),
+			"hoursRemaining": message.HoursRemaining,
+		}).Info("Critical alert below quiet hours floor, ignoring quiet hours")
+		return b.sendDiscordAlert(message, false)
+	}
+
+	if window.Mode == QuietHoursModeQueue {
+		message.QueuedAt = time.Now().UTC()
+		if err := b.queueDiscordAlert(message); err != nil {
+			log.WithField("destination", message.Destination()).WithError(err).Warn("Failed to queue alert during quiet hours, sending silently instead")
+			return b.sendDiscordAlert(message, true)
+		}
+
+		log.WithFields(logrus.Fields{
+			"destination": message.Destination(),
+			"starbaseIDs": message.StarbaseIDs,
+		}).Info("Queued alert until quiet hours end")
+		return nil
+	}
+
+	return b.sendDiscordAlert(message, true)
+}
+
+func (b *Bot) sendDiscordAlert(message *DiscordAlertMessage, silent bool) error {
+	content := message.Content
+	if !silent && len(message.Mention) > 0 {
+		content = fmt.Sprintf("%s %s", message.Mention, content)
+	}
+	if !message.QueuedAt.IsZero() {
+		content = fmt.Sprintf("%s\n*Held back during quiet hours since %s.*", content, message.QueuedAt.Format("2006-01-02 15:04 MST"))
+	}
+
+	channelID := message.ChannelID
+	if len(message.UserID) > 0 {
+		channel, err := b.discord.UserChannelCreate(message.UserID)
+		if err != nil {
+			return errors.Wrap(err, "Failed to create direct message channel")
+		}
+		channelID = channel.ID
+	}
+
+	send := &discordgo.MessageSend{
+		Content: strings.TrimSpace(content),
+	}
+	if message.Embed != nil {
+		send.Embeds = []*discordgo.MessageEmbed{message.Embed}
+	}
+	if silent {
+		send.Flags = discordgo.MessageFlagsSuppressNotifications
+	}
+
+	sent, err := b.discord.ChannelMessageSendComplex(channelID, send)
+	if err != nil {
+		if len(message.UserID) == 0 && len(message.StarbaseIDs) > 0 {
+			metricNotificationFailures.WithLabelValues(DiscordNotifierName).Inc()
+		}
+		return errors.Wrap(err, "Failed to send alert message")
+	}
+
+	if len(message.UserID) == 0 && len(message.StarbaseIDs) > 0 {
+		recordNotificationSent(DiscordNotifierName, b.getAlertSeverity(message.StageIndex), len(message.StarbaseIDs))
+		b.recordAlertMessage(sent.ID, message.StarbaseIDs, message.StageIndex)
+		b.discord.MessageReactionAdd(sent.ChannelID, sent.ID, DiscordEmojiAcknowledge)
+	}
+
+	return nil
+}
+
+func (b *Bot) flushQueuedDiscordAlerts() {
+	now := time.Now().UTC()
+	for _, window := range b.quietHours {
+		if window.Mode != QuietHoursModeQueue || window.Active(now) {
+			continue
+		}
+
+		messages, err := b.retrieveQueuedDiscordAlerts(window.Destination)
+		if err != nil {
+			log.WithField("destination", window.Destination).WithError(err).Warn("Failed to retrieve alerts queued during quiet hours")
+			continue
+		}
+		if len(messages) == 0 {
+			continue
+		}
+
+		latest := make(map[string]int)
+		for i, message := range messages {
+			latest[message.Key()] = i
+		}
+
+		sent := 0
+		dropped := 0
+		for i, message := range messages {
+			if latest[message.Key()] != i {
+				continue
+			}
+
+			if !b.isQueuedDiscordAlertPending(message) {
+				log.WithFields(logrus.Fields{
+					"destination": window.Destination,
+					"starbaseIDs": message.StarbaseIDs,
+				}).Debug("Dropping alert queued during quiet hours, no longer pending")
+				dropped++
+				continue
+			}
+
+			if err = b.sendDiscordAlert(message, false); err != nil {
+				log.WithFields(logrus.Fields{
+					"destination": window.Destination,
+					"starbaseIDs": message.StarbaseIDs,
+				}).WithError(err).Warn("Failed to send alert queued during quiet hours, queueing again")
+				if err = b.queueDiscordAlert(message); err != nil {
+					log.WithFields(logrus.Fields{
+						"destination": window.Destination,
+						"starbaseIDs": message.StarbaseIDs,
+					}).WithError(err).Error("Failed to queue alert again, alert is lost")
+				}
+				continue
+			}
+			sent++
+		}
+
+		log.WithFields(logrus.Fields{
+			"destination": window.Destination,
+			"queued":      len(messages),
+			"sent":        sent,
+			"dropped":     dropped,
+		}).Info("Sent alerts queued during quiet hours")
+	}
+}
+
+func (b *Bot) isQueuedDiscordAlertPending(message *DiscordAlertMessage) bool {
+	// alerts may sit in the queue for hours, so the POS might have been refuelled,
+	// acknowledged or ignored in the meantime
+	if len(message.StarbaseIDs) == 0 {
+		return true
+	}
+
+	for _, starbaseID := range message.StarbaseIDs {
+		if !b.isStarbaseMonitored(starbaseID) {
+			continue
+		}
+
+		pos, err := b.getPOSFromStarbaseID(starbaseID)
+		if err != nil {
+			log.WithField("starbaseID", starbaseID).WithError(err).Warn("Failed to re-evaluate queued alert, assuming still pending")
+			return true
+		}
+
+		stageIndex := b.getStarbaseEscalationStage(pos)
+		if stageIndex == 0 {
+			continue
+		}
+
+		ack, err := b.retrieveStarbaseAcknowledgement(starbaseID)
+		if err != nil && err != redis.ErrNil {
+			log.WithField("starbaseID", starbaseID).WithError(err).Warn("Failed to retrieve starbase acknowledgement for queued alert")
+		}
+		if !ack.Covers(stageIndex) {
+			return true
+		}
+	}
+
+	return false
+}
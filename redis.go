@@ -7,20 +7,40 @@ import (
 	"github.com/Sirupsen/logrus"
 	"github.com/garyburd/redigo/redis"
 	"github.com/pkg/errors"
+	"strconv"
 	"strings"
 	"time"
 )
 
 const (
-	RedisKeyStarbaseList    = "posbot:starbase:list"
-	RedisKeyStarbaseDetails = "posbot:starbase:details"
-	RedisKeyPOS             = "posbot:pos"
-	RedisKeyCommandUsage    = "posbot:command:usage"
-	RedisKeyCommandError    = "posbot:command:error"
-	RedisKeyNotification    = "posbot:notification"
+	RedisKeyStarbaseList       = "posbot:starbase:list"
+	RedisKeyStarbaseDetails    = "posbot:starbase:details"
+	RedisKeyStarbaseSnapshot   = "posbot:starbase:snapshot"
+	RedisKeyStarbaseIgnored    = "posbot:starbase:ignored"
+	RedisKeyStarbaseThreshold  = "posbot:starbase:threshold"
+	RedisKeyPOS                = "posbot:pos"
+	RedisKeyCommandUsage       = "posbot:command:usage"
+	RedisKeyCommandError       = "posbot:command:error"
+	RedisKeyEscalation         = "posbot:escalation"
+	RedisKeyLegacyNotification = "posbot:notification"
+	RedisKeyAcknowledgement    = "posbot:acknowledgement"
+	RedisKeyAlertMessage       = "posbot:alert:message"
+	RedisKeyScheduleLastRun    = "posbot:schedule:lastrun"
+	RedisKeyFuelPrice          = "posbot:price"
+	RedisKeyStarbaseFuel       = "posbot:starbase:fuel"
+	RedisKeyRefuel             = "posbot:refuel"
+	RedisKeyPaginatedMessage   = "posbot:page"
+	RedisKeySubscription       = "posbot:subscription"
+	RedisKeyQuietHoursQueue    = "posbot:quiet:queue"
+	RedisKeyWebhookDeadLetter  = "posbot:webhook:deadletter"
+	RedisKeyAlertHistory       = "posbot:alert:history"
+	RedisKeyAlertOpen          = "posbot:alert:open"
+	RedisAlertHistoryRetention = 100
 )
 
 func (b *Bot) recordCommandUsage(command string) {
+	metricCommandUsage.WithLabelValues(command).Inc()
+
 	r := b.redis.Get()
 	defer r.Close()
 
@@ -31,6 +51,8 @@ func (b *Bot) recordCommandUsage(command string) {
 }
 
 func (b *Bot) recordCommandError(command string) {
+	metricCommandErrors.WithLabelValues(command).Inc()
+
 	r := b.redis.Get()
 	defer r.Close()
 
@@ -188,6 +210,199 @@ func (b *Bot) cacheStarbaseList(starbases *eveapi.StarbaseList) error {
 	return nil
 }
 
+func (b *Bot) retrieveStarbaseSnapshot() (*eveapi.StarbaseList, error) {
+	log.Debug("Retrieving starbase snapshot from redis")
+
+	r := b.redis.Get()
+	defer r.Close()
+
+	data, err := redis.Bytes(r.Do("GET", RedisKeyStarbaseSnapshot))
+	if err == redis.ErrNil {
+		log.Debug("Starbase snapshot not stored in redis")
+		return nil, err
+	} else if err != nil {
+		return nil, errors.Wrap(err, "Failed to retrieve starbase snapshot from redis")
+	}
+
+	starbases := &eveapi.StarbaseList{}
+	if err = json.Unmarshal(data, starbases); err != nil {
+		return nil, errors.Wrap(err, "Failed to parse starbase snapshot from redis")
+	}
+
+	log.WithField("count", len(starbases.Starbases)).Debug("Retrieved starbase snapshot from redis")
+	return starbases, nil
+}
+
+func (b *Bot) storeStarbaseSnapshot(starbases *eveapi.StarbaseList) error {
+	log.WithField("count", len(starbases.Starbases)).Debug("Storing starbase snapshot in redis")
+
+	r := b.redis.Get()
+	defer r.Close()
+
+	data, err := json.Marshal(starbases)
+	if err != nil {
+		return errors.Wrap(err, "Failed to marshal starbase snapshot to JSON")
+	}
+
+	reply, err := redis.String(r.Do("SET", RedisKeyStarbaseSnapshot, data))
+	if err != nil {
+		return errors.Wrap(err, "Failed to store starbase snapshot in redis")
+	} else if !strings.EqualFold(reply, "OK") {
+		return errors.New("Failed to store starbase snapshot in redis")
+	}
+
+	log.WithField("count", len(starbases.Starbases)).Debug("Stored starbase snapshot in redis")
+	return nil
+}
+
+func (b *Bot) retrieveStarbaseIgnore(starbaseID int) (*StarbaseIgnore, error) {
+	log.WithField("starbaseID", starbaseID).Debug("Retrieving starbase ignore from redis")
+
+	r := b.redis.Get()
+	defer r.Close()
+
+	data, err := redis.Bytes(r.Do("HGET", RedisKeyStarbaseIgnored, starbaseID))
+	if err == redis.ErrNil {
+		log.WithField("starbaseID", starbaseID).Debug("Starbase ignore not stored in redis")
+		return nil, err
+	} else if err != nil {
+		return nil, errors.Wrap(err, "Failed to retrieve starbase ignore from redis")
+	}
+
+	ignore := &StarbaseIgnore{}
+	if err = json.Unmarshal(data, ignore); err != nil {
+		return nil, errors.Wrap(err, "Failed to parse starbase ignore from redis")
+	}
+
+	if ignore.Expired() {
+		log.WithFields(logrus.Fields{
+			"starbaseID": starbaseID,
+			"expires":    ignore.Expires,
+		}).Info("Starbase ignore expired, removing")
+		if err = b.deleteStarbaseIgnore(starbaseID); err != nil {
+			log.WithField("starbaseID", starbaseID).WithError(err).Warn("Failed to remove expired starbase ignore")
+		}
+		return nil, redis.ErrNil
+	}
+
+	log.WithFields(logrus.Fields{
+		"starbaseID": starbaseID,
+		"expires":    ignore.Expires,
+	}).Debug("Retrieved starbase ignore from redis")
+	return ignore, nil
+}
+
+func (b *Bot) storeStarbaseIgnore(ignore *StarbaseIgnore) error {
+	log.WithFields(logrus.Fields{
+		"starbaseID": ignore.StarbaseID,
+		"expires":    ignore.Expires,
+	}).Debug("Storing starbase ignore in redis")
+
+	r := b.redis.Get()
+	defer r.Close()
+
+	data, err := json.Marshal(ignore)
+	if err != nil {
+		return errors.Wrap(err, "Failed to marshal starbase ignore to JSON")
+	}
+
+	_, err = r.Do("HSET", RedisKeyStarbaseIgnored, ignore.StarbaseID, data)
+	if err != nil {
+		return errors.Wrap(err, "Failed to store starbase ignore in redis")
+	}
+
+	log.WithFields(logrus.Fields{
+		"starbaseID": ignore.StarbaseID,
+		"expires":    ignore.Expires,
+	}).Debug("Stored starbase ignore in redis")
+	return nil
+}
+
+func (b *Bot) deleteStarbaseIgnore(starbaseID int) error {
+	log.WithField("starbaseID", starbaseID).Debug("Deleting starbase ignore from redis")
+
+	r := b.redis.Get()
+	defer r.Close()
+
+	deleted, err := redis.Int(r.Do("HDEL", RedisKeyStarbaseIgnored, starbaseID))
+	if err != nil {
+		return errors.Wrap(err, "Failed to delete starbase ignore from redis")
+	} else if deleted == 0 {
+		return redis.ErrNil
+	}
+
+	log.WithField("starbaseID", starbaseID).Debug("Deleted starbase ignore from redis")
+	return nil
+}
+
+func (b *Bot) retrieveStarbaseThreshold(starbaseID int) (*FuelThreshold, error) {
+	log.WithField("starbaseID", starbaseID).Debug("Retrieving starbase threshold from redis")
+
+	r := b.redis.Get()
+	defer r.Close()
+
+	data, err := redis.Bytes(r.Do("HGET", RedisKeyStarbaseThreshold, starbaseID))
+	if err == redis.ErrNil {
+		log.WithField("starbaseID", starbaseID).Debug("Starbase threshold not stored in redis")
+		return nil, err
+	} else if err != nil {
+		return nil, errors.Wrap(err, "Failed to retrieve starbase threshold from redis")
+	}
+
+	threshold := &FuelThreshold{}
+	if err = json.Unmarshal(data, threshold); err != nil {
+		return nil, errors.Wrap(err, "Failed to parse starbase threshold from redis")
+	}
+
+	log.WithFields(logrus.Fields{
+		"starbaseID": starbaseID,
+		"warning":    threshold.Warning,
+		"critical":   threshold.Critical,
+	}).Debug("Retrieved starbase threshold from redis")
+	return threshold, nil
+}
+
+func (b *Bot) storeStarbaseThreshold(starbaseID int, threshold *FuelThreshold) error {
+	log.WithFields(logrus.Fields{
+		"starbaseID": starbaseID,
+		"warning":    threshold.Warning,
+		"critical":   threshold.Critical,
+	}).Debug("Storing starbase threshold in redis")
+
+	r := b.redis.Get()
+	defer r.Close()
+
+	data, err := json.Marshal(threshold)
+	if err != nil {
+		return errors.Wrap(err, "Failed to marshal starbase threshold to JSON")
+	}
+
+	_, err = r.Do("HSET", RedisKeyStarbaseThreshold, starbaseID, data)
+	if err != nil {
+		return errors.Wrap(err, "Failed to store starbase threshold in redis")
+	}
+
+	log.WithField("starbaseID", starbaseID).Debug("Stored starbase threshold in redis")
+	return nil
+}
+
+func (b *Bot) deleteStarbaseThreshold(starbaseID int) error {
+	log.WithField("starbaseID", starbaseID).Debug("Deleting starbase threshold from redis")
+
+	r := b.redis.Get()
+	defer r.Close()
+
+	deleted, err := redis.Int(r.Do("HDEL", RedisKeyStarbaseThreshold, starbaseID))
+	if err != nil {
+		return errors.Wrap(err, "Failed to delete starbase threshold from redis")
+	} else if deleted == 0 {
+		return redis.ErrNil
+	}
+
+	log.WithField("starbaseID", starbaseID).Debug("Deleted starbase threshold from redis")
+	return nil
+}
+
 func (b *Bot) retrieveCachedStarbaseDetails(starbaseID int) (*eveapi.StarbaseDetails, error) {
 	log.WithField("starbaseID", starbaseID).Debug("Retrieving cached starbase details from redis")
 
@@ -314,47 +529,694 @@ func (b *Bot) cachePOS(pos *POS) error {
 	return nil
 }
 
-func (b *Bot) recordNotification(starbaseID int, fuelTypeID int, notification int) {
+func (b *Bot) recordEscalation(starbaseID int, fuelTypeID int, stage int, repeat int) {
 	r := b.redis.Get()
 	defer r.Close()
 
-	expiry := 3600
-	if notification == 1 {
-		expiry = b.config.Discord.Notifications.Warning
-	} else if notification == 2 {
-		expiry = b.config.Discord.Notifications.Critical
+	if repeat <= 0 {
+		repeat = 3600
 	}
-	_, err := r.Do("SET", fmt.Sprintf("%s:%d:%d", RedisKeyNotification, starbaseID, fuelTypeID), notification, "EX", expiry)
+	_, err := r.Do("SET", fmt.Sprintf("%s:%d:%d", RedisKeyEscalation, starbaseID, fuelTypeID), stage, "EX", repeat)
 	if err != nil {
 		log.WithFields(logrus.Fields{
-			"starbaseID":   starbaseID,
-			"fuelTypeID":   fuelTypeID,
-			"notification": notification,
-		}).WithError(err).Warn("Failed to record notification in redis")
+			"starbaseID": starbaseID,
+			"fuelTypeID": fuelTypeID,
+			"stage":      stage,
+		}).WithError(err).Warn("Failed to record escalation in redis")
 	}
 }
 
-func (b *Bot) shouldSendNotification(starbaseID int, fuelTypeID, notification int) bool {
+func (b *Bot) migrateLegacyNotifications() error {
 	r := b.redis.Get()
 	defer r.Close()
 
-	sent, err := redis.Int(r.Do("GET", fmt.Sprintf("%s:%d:%d", RedisKeyNotification, starbaseID, fuelTypeID)))
+	legacyKeys := make([]string, 0)
+	cursor := 0
+	for {
+		repl, err := redis.Values(r.Do("SCAN", cursor, "MATCH", fmt.Sprintf("%s:*", RedisKeyLegacyNotification)))
+		if err != nil {
+			return errors.Wrap(err, "Failed to scan legacy notification keys from redis")
+		}
+		if len(repl) < 2 {
+			return errors.Errorf("Failed to scan legacy notification keys from redis, got %d reply values", len(repl))
+		}
+
+		var keys []string
+		if _, err = redis.Scan(repl, &cursor, &keys); err != nil {
+			return errors.Wrap(err, "Failed to parse scanned legacy notification keys from redis")
+		}
+
+		legacyKeys = append(legacyKeys, keys...)
+
+		if cursor == 0 {
+			break
+		}
+	}
+
+	for _, key := range legacyKeys {
+		level, err := redis.Int(r.Do("GET", key))
+		if err == redis.ErrNil {
+			continue
+		} else if err != nil {
+			return errors.Wrapf(err, "Failed to retrieve legacy notification %q from redis", key)
+		}
+
+		ttl, err := redis.Int(r.Do("TTL", key))
+		if err != nil {
+			return errors.Wrapf(err, "Failed to retrieve expiry of legacy notification %q from redis", key)
+		}
+
+		// legacy notifications only knew warning (1) and critical (2), the latter maps to the final escalation stage
+		stageIndex := 1
+		if level >= 2 {
+			stageIndex = len(b.config.Discord.Escalation)
+		}
+		if ttl <= 0 {
+			ttl = b.config.Discord.Escalation[stageIndex-1].Repeat
+		}
+
+		escalationKey := RedisKeyEscalation + strings.TrimPrefix(key, RedisKeyLegacyNotification)
+		if _, err = r.Do("SET", escalationKey, stageIndex, "EX", ttl, "NX"); err != nil {
+			return errors.Wrapf(err, "Failed to migrate legacy notification %q in redis", key)
+		}
+		if _, err = r.Do("DEL", key); err != nil {
+			return errors.Wrapf(err, "Failed to delete legacy notification %q from redis", key)
+		}
+
+		log.WithFields(logrus.Fields{
+			"key":   escalationKey,
+			"stage": stageIndex,
+		}).Debug("Migrated legacy notification to escalation")
+	}
+
+	if len(legacyKeys) > 0 {
+		log.WithField("count", len(legacyKeys)).Info("Migrated legacy notifications to escalations")
+	}
+
+	return nil
+}
+
+func (b *Bot) shouldEscalate(starbaseID int, fuelTypeID int, stageIndex int, stage *EscalationStage) bool {
+	r := b.redis.Get()
+	defer r.Close()
+
+	sent, err := redis.Int(r.Do("GET", fmt.Sprintf("%s:%d:%d", RedisKeyEscalation, starbaseID, fuelTypeID)))
 	if err == redis.ErrNil {
-		b.recordNotification(starbaseID, fuelTypeID, notification)
+		b.recordEscalation(starbaseID, fuelTypeID, stageIndex, stage.Repeat)
 		return true
 	} else if err != nil {
 		log.WithFields(logrus.Fields{
-			"starbaseID":   starbaseID,
-			"fuelTypeID":   fuelTypeID,
-			"notification": notification,
-		}).WithError(err).Warn("Failed to check notification in redis")
+			"starbaseID": starbaseID,
+			"fuelTypeID": fuelTypeID,
+			"stage":      stageIndex,
+		}).WithError(err).Warn("Failed to check escalation in redis")
 		return true
 	}
 
-	if sent < notification {
-		b.recordNotification(starbaseID, fuelTypeID, notification)
+	if sent < stageIndex {
+		b.recordEscalation(starbaseID, fuelTypeID, stageIndex, stage.Repeat)
 		return true
 	}
 
 	return false
 }
+
+func (b *Bot) clearEscalation(starbaseID int, fuelTypeID int) bool {
+	r := b.redis.Get()
+	defer r.Close()
+
+	deleted, err := redis.Int(r.Do("DEL", fmt.Sprintf("%s:%d:%d", RedisKeyEscalation, starbaseID, fuelTypeID)))
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"starbaseID": starbaseID,
+			"fuelTypeID": fuelTypeID,
+		}).WithError(err).Warn("Failed to clear escalation in redis")
+		return false
+	}
+
+	return deleted > 0
+}
+
+func (b *Bot) openFuelAlert(starbaseID int, fuelTypeID int) {
+	r := b.redis.Get()
+	defer r.Close()
+
+	_, err := r.Do("SADD", RedisKeyAlertOpen, fmt.Sprintf("%d:%d", starbaseID, fuelTypeID))
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"starbaseID": starbaseID,
+			"fuelTypeID": fuelTypeID,
+		}).WithError(err).Warn("Failed to record open fuel alert in redis")
+	}
+}
+
+func (b *Bot) closeFuelAlert(starbaseID int, fuelTypeID int) bool {
+	r := b.redis.Get()
+	defer r.Close()
+
+	removed, err := redis.Int(r.Do("SREM", RedisKeyAlertOpen, fmt.Sprintf("%d:%d", starbaseID, fuelTypeID)))
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"starbaseID": starbaseID,
+			"fuelTypeID": fuelTypeID,
+		}).WithError(err).Warn("Failed to close fuel alert in redis")
+		return false
+	}
+
+	return removed > 0
+}
+
+func (b *Bot) retrieveStarbaseAcknowledgement(starbaseID int) (*StarbaseAcknowledgement, error) {
+	log.WithField("starbaseID", starbaseID).Debug("Retrieving starbase acknowledgement from redis")
+
+	r := b.redis.Get()
+	defer r.Close()
+
+	data, err := redis.Bytes(r.Do("GET", fmt.Sprintf("%s:%d", RedisKeyAcknowledgement, starbaseID)))
+	if err == redis.ErrNil {
+		log.WithField("starbaseID", starbaseID).Debug("Starbase acknowledgement not stored in redis")
+		return nil, err
+	} else if err != nil {
+		return nil, errors.Wrap(err, "Failed to retrieve starbase acknowledgement from redis")
+	}
+
+	ack := &StarbaseAcknowledgement{}
+	if err = json.Unmarshal(data, ack); err != nil {
+		return nil, errors.Wrap(err, "Failed to parse starbase acknowledgement from redis")
+	}
+
+	log.WithFields(logrus.Fields{
+		"starbaseID": starbaseID,
+		"expires":    ack.Expires,
+	}).Debug("Retrieved starbase acknowledgement from redis")
+	return ack, nil
+}
+
+func (b *Bot) storeStarbaseAcknowledgement(ack *StarbaseAcknowledgement) error {
+	log.WithFields(logrus.Fields{
+		"starbaseID": ack.StarbaseID,
+		"expires":    ack.Expires,
+	}).Debug("Storing starbase acknowledgement in redis")
+
+	r := b.redis.Get()
+	defer r.Close()
+
+	data, err := json.Marshal(ack)
+	if err != nil {
+		return errors.Wrap(err, "Failed to marshal starbase acknowledgement to JSON")
+	}
+
+	expiry := ack.Expires.Sub(time.Now().UTC())
+	if int(expiry.Seconds()) <= 0 {
+		return errors.Errorf("Starbase acknowledgement expiry %v is not in the future", expiry)
+	}
+
+	reply, err := redis.String(r.Do("SET", fmt.Sprintf("%s:%d", RedisKeyAcknowledgement, ack.StarbaseID), data, "EX", int(expiry.Seconds())))
+	if err != nil {
+		return errors.Wrap(err, "Failed to store starbase acknowledgement in redis")
+	} else if !strings.EqualFold(reply, "OK") {
+		return errors.New("Failed to store starbase acknowledgement in redis")
+	}
+
+	log.WithFields(logrus.Fields{
+		"starbaseID": ack.StarbaseID,
+		"expires":    ack.Expires,
+	}).Debug("Stored starbase acknowledgement in redis")
+	return nil
+}
+
+func (b *Bot) deleteStarbaseAcknowledgement(starbaseID int) {
+	r := b.redis.Get()
+	defer r.Close()
+
+	_, err := r.Do("DEL", fmt.Sprintf("%s:%d", RedisKeyAcknowledgement, starbaseID))
+	if err != nil {
+		log.WithField("starbaseID", starbaseID).WithError(err).Warn("Failed to delete starbase acknowledgement from redis")
+	}
+}
+
+func (b *Bot) recordAlertMessage(messageID string, starbaseIDs []int, stage int) {
+	r := b.redis.Get()
+	defer r.Close()
+
+	ids := make([]string, 0)
+	for _, id := range starbaseIDs {
+		ids = append(ids, strconv.Itoa(id))
+	}
+
+	_, err := r.Do("SET", fmt.Sprintf("%s:%s", RedisKeyAlertMessage, messageID), fmt.Sprintf("%d:%s", stage, strings.Join(ids, ",")), "EX", 7*24*3600)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"messageID":   messageID,
+			"starbaseIDs": starbaseIDs,
+			"stage":       stage,
+		}).WithError(err).Warn("Failed to record alert message in redis")
+	}
+}
+
+func (b *Bot) retrieveAlertMessage(messageID string) ([]int, int, error) {
+	r := b.redis.Get()
+	defer r.Close()
+
+	alert, err := redis.String(r.Do("GET", fmt.Sprintf("%s:%s", RedisKeyAlertMessage, messageID)))
+	if err == redis.ErrNil {
+		return nil, 0, err
+	} else if err != nil {
+		return nil, 0, errors.Wrap(err, "Failed to retrieve alert message from redis")
+	}
+
+	parts := strings.SplitN(alert, ":", 2)
+	if len(parts) != 2 {
+		return nil, 0, errors.New("Failed to parse alert message from redis")
+	}
+
+	stage, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "Failed to parse alert message stage from redis")
+	}
+
+	starbaseIDs := make([]int, 0)
+	for _, id := range strings.Split(parts[1], ",") {
+		starbaseID, err := strconv.Atoi(id)
+		if err != nil {
+			return nil, 0, errors.Wrap(err, "Failed to parse alert message starbase from redis")
+		}
+		starbaseIDs = append(starbaseIDs, starbaseID)
+	}
+
+	return starbaseIDs, stage, nil
+}
+
+func (b *Bot) retrieveScheduledTaskLastRun(name string) (time.Time, error) {
+	r := b.redis.Get()
+	defer r.Close()
+
+	lastRun, err := redis.Int64(r.Do("HGET", RedisKeyScheduleLastRun, name))
+	if err == redis.ErrNil {
+		return time.Time{}, err
+	} else if err != nil {
+		return time.Time{}, errors.Wrap(err, "Failed to retrieve scheduled task last run from redis")
+	}
+
+	return time.Unix(lastRun, 0).UTC(), nil
+}
+
+func (b *Bot) recordScheduledTaskLastRun(name string, lastRun time.Time) {
+	r := b.redis.Get()
+	defer r.Close()
+
+	_, err := r.Do("HSET", RedisKeyScheduleLastRun, name, lastRun.Unix())
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"task":    name,
+			"lastRun": lastRun,
+		}).WithError(err).Warn("Failed to record scheduled task last run in redis")
+	}
+}
+
+func (b *Bot) retrieveCachedFuelPrice(typeID int) (float64, error) {
+	r := b.redis.Get()
+	defer r.Close()
+
+	price, err := redis.Float64(r.Do("GET", fmt.Sprintf("%s:%d", RedisKeyFuelPrice, typeID)))
+	if err == redis.ErrNil {
+		log.WithField("typeID", typeID).Debug("Fuel price not cached in redis")
+		return 0, err
+	} else if err != nil {
+		return 0, errors.Wrap(err, "Failed to retrieve fuel price from redis")
+	}
+
+	return price, nil
+}
+
+func (b *Bot) cacheFuelPrice(typeID int, price float64, expiry int) error {
+	r := b.redis.Get()
+	defer r.Close()
+
+	_, err := r.Do("SET", fmt.Sprintf("%s:%d", RedisKeyFuelPrice, typeID), price, "EX", expiry)
+	if err != nil {
+		return errors.Wrap(err, "Failed to cache fuel price in redis")
+	}
+
+	return nil
+}
+
+func (b *Bot) retrieveStarbaseFuelSnapshot(starbaseID int) (*eveapi.StarbaseDetails, error) {
+	r := b.redis.Get()
+	defer r.Close()
+
+	data, err := redis.Bytes(r.Do("HGET", RedisKeyStarbaseFuel, starbaseID))
+	if err == redis.ErrNil {
+		return nil, err
+	} else if err != nil {
+		return nil, errors.Wrap(err, "Failed to retrieve starbase fuel snapshot from redis")
+	}
+
+	starbase := &eveapi.StarbaseDetails{}
+	if err = json.Unmarshal(data, starbase); err != nil {
+		return nil, errors.Wrap(err, "Failed to parse starbase fuel snapshot from redis")
+	}
+
+	return starbase, nil
+}
+
+func (b *Bot) storeStarbaseFuelSnapshot(starbaseID int, starbase *eveapi.StarbaseDetails) error {
+	r := b.redis.Get()
+	defer r.Close()
+
+	data, err := json.Marshal(starbase)
+	if err != nil {
+		return errors.Wrap(err, "Failed to marshal starbase fuel snapshot to JSON")
+	}
+
+	_, err = r.Do("HSET", RedisKeyStarbaseFuel, starbaseID, data)
+	if err != nil {
+		return errors.Wrap(err, "Failed to store starbase fuel snapshot in redis")
+	}
+
+	return nil
+}
+
+func (b *Bot) recordRefuelEvent(event *RefuelEvent) error {
+	log.WithFields(logrus.Fields{
+		"starbaseID": event.StarbaseID,
+		"fuelTypeID": event.FuelTypeID,
+		"amount":     event.Amount,
+	}).Debug("Recording refuel event in redis")
+
+	r := b.redis.Get()
+	defer r.Close()
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "Failed to marshal refuel event to JSON")
+	}
+
+	_, err = r.Do("ZADD", RedisKeyRefuel, event.DetectedAt.Unix(), data)
+	if err != nil {
+		return errors.Wrap(err, "Failed to record refuel event in redis")
+	}
+
+	_, err = r.Do("ZREMRANGEBYSCORE", RedisKeyRefuel, "-inf", time.Now().UTC().AddDate(0, 0, -RefuelRetentionDays).Unix())
+	if err != nil {
+		log.WithError(err).Warn("Failed to remove expired refuel events from redis")
+	}
+
+	return nil
+}
+
+func (b *Bot) retrieveRefuelEvents(since time.Time) ([]*RefuelEvent, error) {
+	log.WithField("since", since).Debug("Retrieving refuel events from redis")
+
+	r := b.redis.Get()
+	defer r.Close()
+
+	values, err := redis.ByteSlices(r.Do("ZRANGEBYSCORE", RedisKeyRefuel, since.Unix(), "+inf"))
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to retrieve refuel events from redis")
+	}
+
+	events := make([]*RefuelEvent, 0)
+	for _, data := range values {
+		event := &RefuelEvent{}
+		if err = json.Unmarshal(data, event); err != nil {
+			log.WithError(err).Warn("Failed to parse refuel event from redis")
+			continue
+		}
+		events = append(events, event)
+	}
+
+	log.WithField("count", len(events)).Debug("Retrieved refuel events from redis")
+	return events, nil
+}
+
+func (b *Bot) retrievePaginatedMessage(messageID string) (*PaginatedMessage, error) {
+	r := b.redis.Get()
+	defer r.Close()
+
+	data, err := redis.Bytes(r.Do("GET", fmt.Sprintf("%s:%s", RedisKeyPaginatedMessage, messageID)))
+	if err == redis.ErrNil {
+		return nil, err
+	} else if err != nil {
+		return nil, errors.Wrap(err, "Failed to retrieve paginated message from redis")
+	}
+
+	paginated := &PaginatedMessage{}
+	if err = json.Unmarshal(data, paginated); err != nil {
+		return nil, errors.Wrap(err, "Failed to parse paginated message from redis")
+	}
+
+	return paginated, nil
+}
+
+func (b *Bot) storePaginatedMessage(messageID string, paginated *PaginatedMessage, expiry int) error {
+	r := b.redis.Get()
+	defer r.Close()
+
+	data, err := json.Marshal(paginated)
+	if err != nil {
+		return errors.Wrap(err, "Failed to marshal paginated message to JSON")
+	}
+
+	_, err = r.Do("SET", fmt.Sprintf("%s:%s", RedisKeyPaginatedMessage, messageID), data, "EX", expiry)
+	if err != nil {
+		return errors.Wrap(err, "Failed to store paginated message in redis")
+	}
+
+	return nil
+}
+
+func (b *Bot) retrieveSubscriptions(userID string) ([]*Subscription, error) {
+	r := b.redis.Get()
+	defer r.Close()
+
+	data, err := redis.Bytes(r.Do("HGET", RedisKeySubscription, userID))
+	if err == redis.ErrNil {
+		return make([]*Subscription, 0), nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "Failed to retrieve subscriptions from redis")
+	}
+
+	subscriptions := make([]*Subscription, 0)
+	if err = json.Unmarshal(data, &subscriptions); err != nil {
+		return nil, errors.Wrap(err, "Failed to parse subscriptions from redis")
+	}
+
+	return subscriptions, nil
+}
+
+func (b *Bot) retrieveAllSubscriptions() (map[string][]*Subscription, error) {
+	r := b.redis.Get()
+	defer r.Close()
+
+	values, err := redis.StringMap(r.Do("HGETALL", RedisKeySubscription))
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to retrieve subscriptions from redis")
+	}
+
+	subscriptions := make(map[string][]*Subscription)
+	for userID, data := range values {
+		userSubscriptions := make([]*Subscription, 0)
+		if err = json.Unmarshal([]byte(data), &userSubscriptions); err != nil {
+			log.WithField("userID", userID).WithError(err).Warn("Failed to parse subscriptions from redis")
+			continue
+		}
+		subscriptions[userID] = userSubscriptions
+	}
+
+	return subscriptions, nil
+}
+
+func (b *Bot) storeSubscriptions(userID string, subscriptions []*Subscription) error {
+	log.WithFields(logrus.Fields{
+		"userID": userID,
+		"count":  len(subscriptions),
+	}).Debug("Storing subscriptions in redis")
+
+	r := b.redis.Get()
+	defer r.Close()
+
+	if len(subscriptions) == 0 {
+		_, err := r.Do("HDEL", RedisKeySubscription, userID)
+		if err != nil {
+			return errors.Wrap(err, "Failed to delete subscriptions from redis")
+		}
+		return nil
+	}
+
+	data, err := json.Marshal(subscriptions)
+	if err != nil {
+		return errors.Wrap(err, "Failed to marshal subscriptions to JSON")
+	}
+
+	_, err = r.Do("HSET", RedisKeySubscription, userID, data)
+	if err != nil {
+		return errors.Wrap(err, "Failed to store subscriptions in redis")
+	}
+
+	return nil
+}
+
+func (b *Bot) queueDiscordAlert(message *DiscordAlertMessage) error {
+	r := b.redis.Get()
+	defer r.Close()
+
+	data, err := json.Marshal(message)
+	if err != nil {
+		return errors.Wrap(err, "Failed to marshal queued alert to JSON")
+	}
+
+	_, err = r.Do("RPUSH", fmt.Sprintf("%s:%s", RedisKeyQuietHoursQueue, message.Destination()), data)
+	if err != nil {
+		return errors.Wrap(err, "Failed to queue alert in redis")
+	}
+
+	return nil
+}
+
+func (b *Bot) retrieveQueuedDiscordAlerts(destination string) ([]*DiscordAlertMessage, error) {
+	r := b.redis.Get()
+	defer r.Close()
+
+	key := fmt.Sprintf("%s:%s", RedisKeyQuietHoursQueue, destination)
+
+	r.Send("MULTI")
+	r.Send("LRANGE", key, 0, -1)
+	r.Send("DEL", key)
+	replies, err := redis.Values(r.Do("EXEC"))
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to retrieve queued alerts from redis")
+	}
+
+	values, err := redis.ByteSlices(replies[0], nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to parse queued alerts from redis")
+	}
+
+	messages := make([]*DiscordAlertMessage, 0)
+	for _, data := range values {
+		message := &DiscordAlertMessage{}
+		if err = json.Unmarshal(data, message); err != nil {
+			log.WithField("destination", destination).WithError(err).Warn("Failed to parse queued alert from redis")
+			continue
+		}
+		messages = append(messages, message)
+	}
+
+	return messages, nil
+}
+
+func (b *Bot) storeWebhookDeadLetter(letter *WebhookDeadLetter) error {
+	log.WithFields(logrus.Fields{
+		"url":     letter.URL,
+		"eventID": letter.EventID,
+		"type":    letter.EventType,
+	}).Debug("Storing dead-lettered webhook event in redis")
+
+	r := b.redis.Get()
+	defer r.Close()
+
+	data, err := json.Marshal(letter)
+	if err != nil {
+		return errors.Wrap(err, "Failed to marshal webhook dead letter to JSON")
+	}
+
+	_, err = r.Do("LPUSH", RedisKeyWebhookDeadLetter, data)
+	if err != nil {
+		return errors.Wrap(err, "Failed to store webhook dead letter in redis")
+	}
+
+	_, err = r.Do("LTRIM", RedisKeyWebhookDeadLetter, 0, WebhookDeadLetterRetention-1)
+	if err != nil {
+		log.WithError(err).Warn("Failed to trim webhook dead letters in redis")
+	}
+
+	return nil
+}
+
+func (b *Bot) retrieveWebhookDeadLetters(count int) ([]*WebhookDeadLetter, int, error) {
+	r := b.redis.Get()
+	defer r.Close()
+
+	total, err := redis.Int(r.Do("LLEN", RedisKeyWebhookDeadLetter))
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "Failed to count webhook dead letters in redis")
+	}
+
+	values, err := redis.ByteSlices(r.Do("LRANGE", RedisKeyWebhookDeadLetter, 0, count-1))
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "Failed to retrieve webhook dead letters from redis")
+	}
+
+	letters := make([]*WebhookDeadLetter, 0)
+	for _, data := range values {
+		letter := &WebhookDeadLetter{}
+		if err = json.Unmarshal(data, letter); err != nil {
+			log.WithError(err).Warn("Failed to parse webhook dead letter from redis")
+			continue
+		}
+		letters = append(letters, letter)
+	}
+
+	return letters, total, nil
+}
+
+func (b *Bot) deleteWebhookDeadLetters() error {
+	r := b.redis.Get()
+	defer r.Close()
+
+	_, err := r.Do("DEL", RedisKeyWebhookDeadLetter)
+	if err != nil {
+		return errors.Wrap(err, "Failed to delete webhook dead letters from redis")
+	}
+
+	return nil
+}
+
+func (b *Bot) recordAlertHistory(alerts []*FuelAlert) error {
+	r := b.redis.Get()
+	defer r.Close()
+
+	args := redis.Args{}.Add(RedisKeyAlertHistory)
+	for _, alert := range alerts {
+		data, err := json.Marshal(newAlertHistoryEntry(alert))
+		if err != nil {
+			return errors.Wrap(err, "Failed to marshal alert history entry to JSON")
+		}
+		args = args.Add(data)
+	}
+
+	_, err := r.Do("LPUSH", args...)
+	if err != nil {
+		return errors.Wrap(err, "Failed to store alert history in redis")
+	}
+
+	_, err = r.Do("LTRIM", RedisKeyAlertHistory, 0, RedisAlertHistoryRetention-1)
+	if err != nil {
+		log.WithError(err).Warn("Failed to trim alert history in redis")
+	}
+
+	return nil
+}
+
+func (b *Bot) retrieveAlertHistory(count int) ([]*AlertHistoryEntry, error) {
+	r := b.redis.Get()
+	defer r.Close()
+
+	values, err := redis.ByteSlices(r.Do("LRANGE", RedisKeyAlertHistory, 0, count-1))
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to retrieve alert history from redis")
+	}
+
+	entries := make([]*AlertHistoryEntry, 0)
+	for _, data := range values {
+		entry := &AlertHistoryEntry{}
+		if err = json.Unmarshal(data, entry); err != nil {
+			log.WithError(err).Warn("Failed to parse alert history entry from redis")
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"github.com/MorpheusXAUT/eveapi"
+	"github.com/Sirupsen/logrus"
+	"github.com/garyburd/redigo/redis"
+	"time"
+)
+
+const (
+	RefuelRetentionDays = 90
+	DefaultRefuelDays   = 7
+)
+
+type RefuelEvent struct {
+	StarbaseID   int       `json:"starbaseID"`
+	FuelTypeID   int       `json:"fuelTypeID"`
+	FuelTypeName string    `json:"fuelTypeName"`
+	Amount       int       `json:"amount"`
+	Quantity     int       `json:"quantity"`
+	DetectedAt   time.Time `json:"detectedAt"`
+}
+
+func (b *Bot) checkStarbaseRefuels(starbaseID int, details *eveapi.StarbaseDetails) {
+	previous, err := b.retrieveStarbaseFuelSnapshot(starbaseID)
+	if err != nil && err != redis.ErrNil {
+		log.WithField("starbaseID", starbaseID).WithError(err).Warn("Failed to retrieve starbase fuel snapshot")
+		return
+	}
+
+	if err = b.storeStarbaseFuelSnapshot(starbaseID, details); err != nil {
+		log.WithField("starbaseID", starbaseID).WithError(err).Warn("Failed to store starbase fuel snapshot")
+	}
+
+	if previous == nil {
+		log.WithField("starbaseID", starbaseID).Debug("No previous fuel snapshot for starbase, skipping refuel detection")
+		return
+	}
+
+	if !previous.CurrentTime.Time.Before(details.CurrentTime.Time) {
+		return
+	}
+
+	quantities := make(map[int]int)
+	for _, fuel := range previous.Fuel {
+		quantities[fuel.TypeID] = fuel.Quantity
+	}
+
+	for _, fuel := range details.Fuel {
+		amount := fuel.Quantity - quantities[fuel.TypeID]
+		if amount <= 0 {
+			continue
+		}
+
+		event := &RefuelEvent{
+			StarbaseID: starbaseID,
+			FuelTypeID: fuel.TypeID,
+			Amount:     amount,
+			Quantity:   fuel.Quantity,
+			DetectedAt: details.CurrentTime.Time.UTC(),
+		}
+
+		start := time.Now()
+		fuelType, _, err := b.esi.UniverseApi.GetUniverseTypesTypeId(int32(fuel.TypeID), nil)
+		observeEVEAPIRequest("GetUniverseTypesTypeId", start, err)
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"starbaseID": starbaseID,
+				"typeID":     fuel.TypeID,
+			}).WithError(err).Warn("Failed to get fuel name for refuel event")
+		} else {
+			event.FuelTypeName = fuelType.Name
+		}
+
+		if err = b.recordRefuelEvent(event); err != nil {
+			log.WithFields(logrus.Fields{
+				"starbaseID": starbaseID,
+				"fuelTypeID": fuel.TypeID,
+			}).WithError(err).Warn("Failed to record refuel event")
+			continue
+		}
+
+		log.WithFields(logrus.Fields{
+			"starbaseID": starbaseID,
+			"fuelTypeID": fuel.TypeID,
+			"amount":     amount,
+			"quantity":   fuel.Quantity,
+		}).Info("Detected starbase refuel")
+	}
+}
+
+func (b *Bot) getRefuelEvents(starbaseID int, days int) ([]*RefuelEvent, error) {
+	events, err := b.retrieveRefuelEvents(time.Now().UTC().AddDate(0, 0, -days))
+	if err != nil {
+		return nil, err
+	}
+
+	if starbaseID <= 0 {
+		return events, nil
+	}
+
+	filtered := make([]*RefuelEvent, 0)
+	for _, event := range events {
+		if event.StarbaseID == starbaseID {
+			filtered = append(filtered, event)
+		}
+	}
+
+	return filtered, nil
+}
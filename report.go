@@ -0,0 +1,98 @@
+package main
+
+import (
+	"github.com/pkg/errors"
+	"sort"
+	"time"
+)
+
+type FuelReport struct {
+	GeneratedAt time.Time
+	Days        int
+	Entries     []*FuelReportEntry
+	TotalBlocks int
+	TotalCost   *FuelCost
+	Pricing     bool
+}
+
+type FuelReportEntry struct {
+	POS    *POS
+	Blocks int
+	Cost   *FuelCost
+}
+
+func (b *Bot) buildFuelReport() (*FuelReport, error) {
+	err := b.updateMonitoredStarbaseDetails()
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to update monitored starbase details")
+	}
+
+	poses, err := b.getMonitoredPOSes()
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to retrieve monitored POSes")
+	}
+
+	sort.Slice(poses, func(i, j int) bool {
+		return poses[i].HoursRemaining() < poses[j].HoursRemaining()
+	})
+
+	report := &FuelReport{
+		GeneratedAt: time.Now().UTC(),
+		Days:        b.config.Discord.Report.TopUpDays,
+		Entries:     make([]*FuelReportEntry, 0),
+		TotalCost:   &FuelCost{},
+		Pricing:     b.config.EVE.Pricing.Enabled,
+	}
+	for _, pos := range poses {
+		entry := &FuelReportEntry{
+			POS:    pos,
+			Blocks: pos.FuelBlocksRequired(report.Days),
+		}
+		report.TotalBlocks += entry.Blocks
+
+		if report.Pricing {
+			entry.Cost, err = b.getPOSFuelCost(pos)
+			if err != nil {
+				log.WithField("starbaseID", pos.ID).WithError(err).Warn("Failed to calculate POS fuel cost for fuel report")
+			} else {
+				report.TotalCost.Add(entry.Cost)
+			}
+		}
+
+		report.Entries = append(report.Entries, entry)
+	}
+
+	return report, nil
+}
+
+func (b *Bot) sendFuelReport() error {
+	report, err := b.buildFuelReport()
+	if err != nil {
+		log.WithError(err).Error("Failed to build fuel report")
+		if b.config.Discord.Verbose {
+			b.discord.ChannelMessageSend(b.getReportChannelID(), ":warning: There was an error retrieving monitored POSes for the fuel report :warning:")
+		}
+		return errors.Wrap(err, "Failed to build fuel report")
+	}
+
+	if err = b.sendDiscordFuelReport(report); err != nil {
+		return err
+	}
+
+	if b.email != nil {
+		if err = b.email.SendReport(report); err != nil {
+			// the Discord report is already out, retrying would post it twice
+			log.WithError(err).Warn("Failed to send fuel report email")
+		}
+	}
+
+	return nil
+}
+
+func (b *Bot) getReportChannelID() string {
+	if len(b.config.Discord.Report.ChannelID) > 0 {
+		return b.config.Discord.Report.ChannelID
+	}
+
+	return b.config.Discord.ChannelID
+}
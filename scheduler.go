@@ -0,0 +1,126 @@
+package main
+
+import (
+	"github.com/Sirupsen/logrus"
+	"github.com/garyburd/redigo/redis"
+	"github.com/pkg/errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ScheduledTaskFuelReport = "report"
+)
+
+type Schedule struct {
+	Daily   bool
+	Weekday time.Weekday
+	Hour    int
+	Minute  int
+}
+
+func parseSchedule(str string) (*Schedule, error) {
+	parts := strings.Fields(strings.ToLower(str))
+	if len(parts) != 2 {
+		return nil, errors.New("Schedule must be in the form of \"daily HH:MM\" or \"WEEKDAY HH:MM\"")
+	}
+
+	schedule := &Schedule{}
+	if parts[0] == "daily" {
+		schedule.Daily = true
+	} else {
+		found := false
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			if parts[0] == strings.ToLower(d.String()) {
+				schedule.Weekday = d
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, errors.Errorf("Unknown schedule day %q", parts[0])
+		}
+	}
+
+	clock := strings.SplitN(parts[1], ":", 2)
+	if len(clock) != 2 {
+		return nil, errors.Errorf("Invalid schedule time %q", parts[1])
+	}
+
+	var err error
+	schedule.Hour, err = strconv.Atoi(clock[0])
+	if err != nil || schedule.Hour < 0 || schedule.Hour > 23 {
+		return nil, errors.Errorf("Invalid schedule hour %q", clock[0])
+	}
+	schedule.Minute, err = strconv.Atoi(clock[1])
+	if err != nil || schedule.Minute < 0 || schedule.Minute > 59 {
+		return nil, errors.Errorf("Invalid schedule minute %q", clock[1])
+	}
+
+	return schedule, nil
+}
+
+func (s *Schedule) Previous(now time.Time) time.Time {
+	now = now.UTC()
+	previous := time.Date(now.Year(), now.Month(), now.Day(), s.Hour, s.Minute, 0, 0, time.UTC)
+	if previous.After(now) {
+		previous = previous.AddDate(0, 0, -1)
+	}
+
+	if !s.Daily {
+		for previous.Weekday() != s.Weekday {
+			previous = previous.AddDate(0, 0, -1)
+		}
+	}
+
+	return previous
+}
+
+func (b *Bot) runScheduledTasks() {
+	if b.config.Discord.Report.Enabled && b.reportSchedule != nil {
+		b.runScheduledTask(ScheduledTaskFuelReport, b.reportSchedule, b.sendFuelReport)
+	}
+	if len(b.quietHours) > 0 {
+		b.flushQueuedDiscordAlerts()
+	}
+}
+
+func (b *Bot) runScheduledTask(name string, schedule *Schedule, task func() error) {
+	due := schedule.Previous(time.Now().UTC())
+
+	lastRun, err := b.retrieveScheduledTaskLastRun(name)
+	if err == redis.ErrNil {
+		log.WithFields(logrus.Fields{
+			"task": name,
+			"due":  due,
+		}).Debug("Scheduled task has never run before, initialising last run")
+		b.recordScheduledTaskLastRun(name, due)
+		return
+	} else if err != nil {
+		log.WithField("task", name).WithError(err).Warn("Failed to retrieve last run of scheduled task")
+		return
+	}
+
+	if !lastRun.Before(due) {
+		return
+	}
+
+	log.WithFields(logrus.Fields{
+		"task":    name,
+		"due":     due,
+		"lastRun": lastRun,
+	}).Info("Running scheduled task")
+
+	// only record the run once the task succeeded, failed runs are retried on the next tick
+	if err = task(); err != nil {
+		log.WithFields(logrus.Fields{
+			"task": name,
+			"due":  due,
+		}).WithError(err).Warn("Scheduled task failed, retrying later")
+		return
+	}
+	b.recordScheduledTaskLastRun(name, due)
+
+	log.WithField("task", name).Info("Finished running scheduled task")
+}
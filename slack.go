@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	SlackMaxAlertsPerMessage = 20
+)
+
+var slackTextEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+type SlackNotifier struct {
+	WebhookURL string
+	Severity   string
+	client     *http.Client
+}
+
+type slackMessage struct {
+	Text   string        `json:"text"`
+	Blocks []*slackBlock `json:"blocks"`
+}
+
+type slackBlock struct {
+	Type     string       `json:"type"`
+	Text     *slackText   `json:"text,omitempty"`
+	Fields   []*slackText `json:"fields,omitempty"`
+	Elements []*slackText `json:"elements,omitempty"`
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+func NewSlackNotifier(webhookURL string, severity string, client *http.Client) *SlackNotifier {
+	return &SlackNotifier{
+		WebhookURL: webhookURL,
+		Severity:   severity,
+		client:     client,
+	}
+}
+
+func (n *SlackNotifier) Name() string {
+	return "slack"
+}
+
+func (n *SlackNotifier) Notify(alerts []*FuelAlert) error {
+	alerts = filterFuelAlerts(alerts, n.Severity)
+
+	for start := 0; start < len(alerts); start += SlackMaxAlertsPerMessage {
+		end := start + SlackMaxAlertsPerMessage
+		if end > len(alerts) {
+			end = len(alerts)
+		}
+
+		if err := n.send(formatSlackFuelAlerts(alerts[start:end])); err != nil {
+			return err
+		}
+
+		recordNotificationsSent(n.Name(), alerts[start:end])
+		log.WithField("count", end-start).Info("Slack fuel alert message sent")
+	}
+
+	return nil
+}
+
+func (n *SlackNotifier) send(message *slackMessage) error {
+	return sendNotifierRequest(n.client, http.MethodPost, n.WebhookURL, nil, message)
+}
+
+func formatSlackFuelAlerts(alerts []*FuelAlert) *slackMessage {
+	critical := countCriticalFuelAlerts(alerts)
+
+	title := fmt.Sprintf(":alarm_clock: %d POS fuel alert(s)", len(alerts))
+	if critical > 0 {
+		title = fmt.Sprintf(":rotating_light: %d POS fuel alert(s), %d critical", len(alerts), critical)
+	}
+
+	blocks := []*slackBlock{
+		{
+			Type: "header",
+			Text: &slackText{Type: "plain_text", Text: title},
+		},
+	}
+
+	for _, alert := range alerts {
+		blocks = append(blocks, &slackBlock{
+			Type: "section",
+			Text: &slackText{
+				Type: "mrkdwn",
+				Text: fmt.Sprintf("*%s* (owned by %s)", escapeSlackText(alert.POS.LocationName), escapeSlackText(alert.POS.OwnerName)),
+			},
+			Fields: []*slackText{
+				{Type: "mrkdwn", Text: fmt.Sprintf("*Fuel*\n%s", escapeSlackText(alert.Fuel.TypeName))},
+				{Type: "mrkdwn", Text: fmt.Sprintf("*Remaining*\n%s", escapeSlackText(alert.Remaining))},
+				{Type: "mrkdwn", Text: fmt.Sprintf("*Severity*\n%s", alert.Severity)},
+				{Type: "mrkdwn", Text: fmt.Sprintf("*POS ID*\n%d", alert.POS.ID)},
+			},
+		})
+	}
+
+	blocks = append(blocks, &slackBlock{
+		Type: "context",
+		Elements: []*slackText{
+			{Type: "mrkdwn", Text: "Sent by POSbot, acknowledge alerts on Discord using `!pos ack POSID`"},
+		},
+	})
+
+	return &slackMessage{
+		Text:   title,
+		Blocks: blocks,
+	}
+}
+
+func escapeSlackText(text string) string {
+	return slackTextEscaper.Replace(text)
+}
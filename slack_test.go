@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func newTestFuelAlert(id int, locationName string, severity string) *FuelAlert {
+	return &FuelAlert{
+		POS: &POS{
+			ID:           id,
+			LocationName: locationName,
+			OwnerName:    "Test Corporation",
+		},
+		Fuel: POSFuel{
+			Type:               POSFuelTypeFuelBlock,
+			TypeName:           "Nitrogen Fuel Block",
+			ConstantlyRequired: true,
+			HoursRemaining:     12,
+		},
+		Remaining: "12 hours",
+		Severity:  severity,
+	}
+}
+
+type slackTestServer struct {
+	*httptest.Server
+	mutex    sync.Mutex
+	messages []*slackMessage
+	status   int
+}
+
+func newSlackTestServer(t *testing.T, status int) *slackTestServer {
+	server := &slackTestServer{status: status}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("Unexpected method %q", r.Method)
+		}
+		if contentType := r.Header.Get("Content-Type"); contentType != "application/json" {
+			t.Errorf("Unexpected content type %q", contentType)
+		}
+
+		message := &slackMessage{}
+		if err := json.NewDecoder(r.Body).Decode(message); err != nil {
+			t.Errorf("Failed to decode Slack message: %v", err)
+		}
+
+		server.mutex.Lock()
+		server.messages = append(server.messages, message)
+		server.mutex.Unlock()
+
+		w.WriteHeader(server.status)
+	}))
+
+	return server
+}
+
+func TestSlackNotifierNotify(t *testing.T) {
+	server := newSlackTestServer(t, http.StatusOK)
+	defer server.Close()
+
+	alerts := make([]*FuelAlert, 0)
+	for i := 0; i < SlackMaxAlertsPerMessage+5; i++ {
+		alerts = append(alerts, newTestFuelAlert(i+1, fmt.Sprintf("Moon %d", i+1), AlertSeverityWarning))
+	}
+	alerts[0].Severity = AlertSeverityCritical
+
+	notifier := NewSlackNotifier(server.URL, AlertSeverityWarning, server.Client())
+	if err := notifier.Notify(alerts); err != nil {
+		t.Fatalf("Notify returned error: %v", err)
+	}
+
+	if len(server.messages) != 2 {
+		t.Fatalf("Expected 2 messages, got %d", len(server.messages))
+	}
+	if text := server.messages[0].Text; text != ":rotating_light: 20 POS fuel alert(s), 1 critical" {
+		t.Errorf("Unexpected first message text %q", text)
+	}
+	if text := server.messages[1].Text; text != ":alarm_clock: 5 POS fuel alert(s)" {
+		t.Errorf("Unexpected second message text %q", text)
+	}
+
+	// header, one section per alert and the context footer
+	if blocks := len(server.messages[0].Blocks); blocks != SlackMaxAlertsPerMessage+2 {
+		t.Errorf("Expected %d blocks, got %d", SlackMaxAlertsPerMessage+2, blocks)
+	}
+}
+
+func TestSlackNotifierNotifySeverity(t *testing.T) {
+	server := newSlackTestServer(t, http.StatusOK)
+	defer server.Close()
+
+	notifier := NewSlackNotifier(server.URL, AlertSeverityCritical, server.Client())
+	if err := notifier.Notify([]*FuelAlert{newTestFuelAlert(1, "Moon 1", AlertSeverityWarning)}); err != nil {
+		t.Fatalf("Notify returned error: %v", err)
+	}
+	if len(server.messages) != 0 {
+		t.Fatalf("Expected no messages for warnings only, got %d", len(server.messages))
+	}
+
+	if err := notifier.Notify([]*FuelAlert{newTestFuelAlert(1, "Moon 1", AlertSeverityWarning), newTestFuelAlert(2, "Moon 2", AlertSeverityCritical)}); err != nil {
+		t.Fatalf("Notify returned error: %v", err)
+	}
+	if len(server.messages) != 1 {
+		t.Fatalf("Expected 1 message, got %d", len(server.messages))
+	}
+	if text := server.messages[0].Blocks[1].Text.Text; !strings.Contains(text, "Moon 2") {
+		t.Errorf("Expected critical alert for Moon 2, got %q", text)
+	}
+}
+
+func TestSlackNotifierNotifyEscaping(t *testing.T) {
+	server := newSlackTestServer(t, http.StatusOK)
+	defer server.Close()
+
+	alert := newTestFuelAlert(1, "<!channel> Moon & Co", AlertSeverityWarning)
+	alert.POS.OwnerName = "<https://example.com|Corp>"
+
+	notifier := NewSlackNotifier(server.URL, AlertSeverityWarning, server.Client())
+	if err := notifier.Notify([]*FuelAlert{alert}); err != nil {
+		t.Fatalf("Notify returned error: %v", err)
+	}
+
+	text := server.messages[0].Blocks[1].Text.Text
+	expected := "*&lt;!channel&gt; Moon &amp; Co* (owned by &lt;https://example.com|Corp&gt;)"
+	if text != expected {
+		t.Errorf("Expected escaped text %q, got %q", expected, text)
+	}
+}
+
+func TestSlackNotifierNotifyError(t *testing.T) {
+	server := newSlackTestServer(t, http.StatusInternalServerError)
+	defer server.Close()
+
+	notifier := NewSlackNotifier(server.URL, AlertSeverityWarning, server.Client())
+	if err := notifier.Notify([]*FuelAlert{newTestFuelAlert(1, "Moon 1", AlertSeverityWarning)}); err == nil {
+		t.Fatal("Expected error for failed webhook request")
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	SubscriptionTypeStarbase     = "pos"
+	SubscriptionTypeCorporation  = "corp"
+	SubscriptionTypeRegion       = "region"
+	SubscriptionSeverityWarning  = "warning"
+	SubscriptionSeverityCritical = "critical"
+)
+
+type Subscription struct {
+	Type     string    `json:"type"`
+	Target   string    `json:"target"`
+	Severity string    `json:"severity"`
+	Created  time.Time `json:"created"`
+}
+
+func parseSubscription(target string, severity string) (*Subscription, error) {
+	if len(severity) == 0 {
+		severity = SubscriptionSeverityWarning
+	}
+
+	subscription := &Subscription{
+		Severity: strings.ToLower(severity),
+		Created:  time.Now().UTC(),
+	}
+
+	parts := strings.Fields(target)
+	if len(parts) == 0 {
+		return nil, errors.New("Missing subscription target")
+	}
+
+	switch strings.ToLower(parts[0]) {
+	case SubscriptionTypeCorporation, SubscriptionTypeRegion:
+		if len(parts) < 2 {
+			return nil, errors.Errorf("Missing name for subscription type %q", parts[0])
+		}
+		subscription.Type = strings.ToLower(parts[0])
+		subscription.Target = normalizeFilterValue(strings.Join(parts[1:], " "))
+	default:
+		if len(parts) != 1 {
+			return nil, errors.Errorf("Invalid subscription target %q", target)
+		}
+		starbaseID, err := strconv.Atoi(parts[0])
+		if err != nil || starbaseID <= 0 {
+			return nil, errors.Errorf("Invalid subscription target %q", target)
+		}
+		subscription.Type = SubscriptionTypeStarbase
+		subscription.Target = strconv.Itoa(starbaseID)
+	}
+
+	return subscription, nil
+}
+
+func (s *Subscription) Key() string {
+	return fmt.Sprintf("%s:%s", s.Type, strings.ToLower(s.Target))
+}
+
+func (s *Subscription) String() string {
+	switch s.Type {
+	case SubscriptionTypeStarbase:
+		return fmt.Sprintf("POS %s", s.Target)
+	case SubscriptionTypeCorporation:
+		return fmt.Sprintf("corporation %s", s.Target)
+	default:
+		return fmt.Sprintf("region %s", s.Target)
+	}
+}
+
+func (s *Subscription) Matches(pos *POS) bool {
+	switch s.Type {
+	case SubscriptionTypeStarbase:
+		return strings.EqualFold(s.Target, strconv.Itoa(pos.ID))
+	case SubscriptionTypeCorporation:
+		return (&POSFilter{Corporation: s.Target}).Matches(pos)
+	case SubscriptionTypeRegion:
+		return (&POSFilter{Region: s.Target}).Matches(pos)
+	}
+
+	return false
+}
+
+func (b *Bot) subscriptionCovers(subscription *Subscription, alert *FuelAlert) bool {
+	if !subscription.Matches(alert.POS) {
+		return false
+	}
+	if strings.EqualFold(subscription.Severity, SubscriptionSeverityCritical) {
+		return alert.Severity == AlertSeverityCritical
+	}
+
+	return true
+}
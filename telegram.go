@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"github.com/Sirupsen/logrus"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	DefaultTelegramBaseURL       = "https://api.telegram.org"
+	TelegramMaxMessageLength     = 4096
+	TelegramMessageHeaderReserve = 128
+)
+
+type TelegramRoute struct {
+	ChatID   string `json:"chatID"`
+	Severity string `json:"severity"`
+	Mention  string `json:"mention"`
+}
+
+type TelegramNotifier struct {
+	BaseURL string
+	Token   string
+	Routes  []TelegramRoute
+	client  *http.Client
+}
+
+type telegramMessage struct {
+	ChatID              string `json:"chat_id"`
+	Text                string `json:"text"`
+	ParseMode           string `json:"parse_mode"`
+	DisableNotification bool   `json:"disable_notification"`
+}
+
+func NewTelegramNotifier(baseURL string, token string, routes []TelegramRoute, client *http.Client) *TelegramNotifier {
+	return &TelegramNotifier{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		Token:   token,
+		Routes:  routes,
+		client:  client,
+	}
+}
+
+func (n *TelegramNotifier) Name() string {
+	return "telegram"
+}
+
+func (n *TelegramNotifier) Notify(alerts []*FuelAlert) error {
+	var lastErr error
+	for _, route := range n.Routes {
+		filtered := filterFuelAlerts(alerts, route.Severity)
+		if len(filtered) == 0 {
+			continue
+		}
+
+		for _, chunk := range splitTelegramFuelAlerts(filtered, TelegramMaxMessageLength-TelegramMessageHeaderReserve-utf8.RuneCountInString(route.Mention)) {
+			if err := n.send(route, chunk); err != nil {
+				log.WithField("chatID", route.ChatID).WithError(err).Warn("Failed to send Telegram message")
+				lastErr = err
+				break
+			}
+
+			recordNotificationsSent(n.Name(), chunk)
+			log.WithFields(logrus.Fields{
+				"chatID": route.ChatID,
+				"count":  len(chunk),
+			}).Info("Telegram fuel alert message sent")
+		}
+	}
+
+	return lastErr
+}
+
+func (n *TelegramNotifier) send(route TelegramRoute, alerts []*FuelAlert) error {
+	critical := countCriticalFuelAlerts(alerts)
+
+	lines := make([]string, 0)
+	if critical > 0 {
+		header := fmt.Sprintf("🚨 <b>%d POS fuel alert(s), %d critical</b>", len(alerts), critical)
+		if len(route.Mention) > 0 {
+			header = fmt.Sprintf("%s %s", route.Mention, header)
+		}
+		lines = append(lines, header)
+	} else {
+		lines = append(lines, fmt.Sprintf("⏰ <b>%d POS fuel alert(s)</b>", len(alerts)))
+	}
+	for _, alert := range alerts {
+		lines = append(lines, formatTelegramFuelAlert(alert))
+	}
+
+	return sendNotifierRequest(n.client, http.MethodPost, fmt.Sprintf("%s/bot%s/sendMessage", n.BaseURL, n.Token), nil, &telegramMessage{
+		ChatID:              route.ChatID,
+		Text:                strings.Join(lines, "\n"),
+		ParseMode:           "HTML",
+		DisableNotification: critical == 0,
+	})
+}
+
+func formatTelegramFuelAlert(alert *FuelAlert) string {
+	return fmt.Sprintf("• %s", formatFuelAlertHTML(alert))
+}
+
+func splitTelegramFuelAlerts(alerts []*FuelAlert, limit int) [][]*FuelAlert {
+	chunks := make([][]*FuelAlert, 0)
+	chunk := make([]*FuelAlert, 0)
+	length := 0
+	for _, alert := range alerts {
+		// +1 for the newline separating the alert from the previous line
+		alertLength := utf8.RuneCountInString(formatTelegramFuelAlert(alert)) + 1
+		if len(chunk) > 0 && length+alertLength > limit {
+			chunks = append(chunks, chunk)
+			chunk = make([]*FuelAlert, 0)
+			length = 0
+		}
+
+		chunk = append(chunk, alert)
+		length += alertLength
+	}
+	if len(chunk) > 0 {
+		chunks = append(chunks, chunk)
+	}
+
+	return chunks
+}
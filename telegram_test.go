@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+type telegramTestServer struct {
+	*httptest.Server
+	messages []*telegramMessage
+}
+
+func newTelegramTestServer(t *testing.T, token string) *telegramTestServer {
+	server := &telegramTestServer{}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != fmt.Sprintf("/bot%s/sendMessage", token) {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"ok":false,"error_code":404,"description":"Not Found"}`))
+			return
+		}
+
+		message := &telegramMessage{}
+		if err := json.NewDecoder(r.Body).Decode(message); err != nil {
+			t.Errorf("Failed to decode Telegram message: %v", err)
+		}
+		if length := utf8.RuneCountInString(message.Text); length > TelegramMaxMessageLength {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"ok":false,"error_code":400,"description":"Bad Request: message is too long"}`))
+			return
+		}
+
+		server.messages = append(server.messages, message)
+		w.Write([]byte(`{"ok":true}`))
+	}))
+
+	return server
+}
+
+func TestTelegramNotifierNotify(t *testing.T) {
+	server := newTelegramTestServer(t, "123:secret")
+	defer server.Close()
+
+	notifier := NewTelegramNotifier(server.URL+"/", "123:secret", []TelegramRoute{
+		{ChatID: "-1001", Severity: AlertSeverityWarning, Mention: "@jfpilots"},
+		{ChatID: "-1002", Severity: AlertSeverityCritical},
+	}, server.Client())
+
+	alerts := []*FuelAlert{
+		newTestFuelAlert(1, "Moon <1>", AlertSeverityWarning),
+		newTestFuelAlert(2, "Moon 2", AlertSeverityCritical),
+	}
+	if err := notifier.Notify(alerts); err != nil {
+		t.Fatalf("Notify returned error: %v", err)
+	}
+
+	if len(server.messages) != 2 {
+		t.Fatalf("Expected 2 messages, got %d", len(server.messages))
+	}
+
+	all := server.messages[0]
+	if all.ChatID != "-1001" || all.ParseMode != "HTML" || all.DisableNotification {
+		t.Errorf("Unexpected message options %+v", all)
+	}
+	if !strings.HasPrefix(all.Text, "@jfpilots 🚨 <b>2 POS fuel alert(s), 1 critical</b>") {
+		t.Errorf("Unexpected message header %q", all.Text)
+	}
+	if !strings.Contains(all.Text, "<b>Moon &lt;1&gt;</b>") {
+		t.Errorf("Expected escaped location in %q", all.Text)
+	}
+
+	critical := server.messages[1]
+	if critical.ChatID != "-1002" || strings.Contains(critical.Text, "POS ID 1)") {
+		t.Errorf("Expected only the critical alert for chat -1002, got %+v", critical)
+	}
+}
+
+func TestTelegramNotifierNotifyWarningsSilent(t *testing.T) {
+	server := newTelegramTestServer(t, "123:secret")
+	defer server.Close()
+
+	notifier := NewTelegramNotifier(server.URL, "123:secret", []TelegramRoute{
+		{ChatID: "-1001", Mention: "@jfpilots"},
+	}, server.Client())
+	if err := notifier.Notify([]*FuelAlert{newTestFuelAlert(1, "Moon 1", AlertSeverityWarning)}); err != nil {
+		t.Fatalf("Notify returned error: %v", err)
+	}
+
+	if len(server.messages) != 1 {
+		t.Fatalf("Expected 1 message, got %d", len(server.messages))
+	}
+	if !server.messages[0].DisableNotification || strings.Contains(server.messages[0].Text, "@jfpilots") {
+		t.Errorf("Expected silent message without mention, got %+v", server.messages[0])
+	}
+}
+
+func TestTelegramNotifierNotifySplit(t *testing.T) {
+	server := newTelegramTestServer(t, "123:secret")
+	defer server.Close()
+
+	alerts := make([]*FuelAlert, 0)
+	for i := 0; i < 100; i++ {
+		alerts = append(alerts, newTestFuelAlert(i+1, fmt.Sprintf("Some Rather Long System Name %d - Moon %d", i+1, i+1), AlertSeverityWarning))
+	}
+
+	notifier := NewTelegramNotifier(server.URL, "123:secret", []TelegramRoute{{ChatID: "-1001"}}, server.Client())
+	if err := notifier.Notify(alerts); err != nil {
+		t.Fatalf("Notify returned error: %v", err)
+	}
+
+	if len(server.messages) < 2 {
+		t.Fatalf("Expected alerts to be split across multiple messages, got %d", len(server.messages))
+	}
+
+	count := 0
+	for _, message := range server.messages {
+		count += strings.Count(message.Text, "\n")
+	}
+	if count != len(alerts) {
+		t.Errorf("Expected %d alerts across all messages, got %d", len(alerts), count)
+	}
+}
+
+func TestTelegramNotifierNotifyRedactsToken(t *testing.T) {
+	server := newTelegramTestServer(t, "123:secret")
+	server.Close()
+
+	notifier := NewTelegramNotifier(server.URL, "123:secret", []TelegramRoute{{ChatID: "-1001"}}, server.Client())
+	err := notifier.Notify([]*FuelAlert{newTestFuelAlert(1, "Moon 1", AlertSeverityWarning)})
+	if err == nil {
+		t.Fatal("Expected error for unreachable Bot API")
+	}
+	if strings.Contains(err.Error(), "secret") {
+		t.Errorf("Expected token to be redacted from error %q", err.Error())
+	}
+}
+
+func TestTelegramNotifierNotifyError(t *testing.T) {
+	server := newTelegramTestServer(t, "123:secret")
+	defer server.Close()
+
+	notifier := NewTelegramNotifier(server.URL, "456:invalid", []TelegramRoute{{ChatID: "-1001"}}, server.Client())
+	if err := notifier.Notify([]*FuelAlert{newTestFuelAlert(1, "Moon 1", AlertSeverityWarning)}); err == nil {
+		t.Fatal("Expected error for rejected Bot API request")
+	}
+}
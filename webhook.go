@@ -0,0 +1,377 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/MorpheusXAUT/eveapi"
+	"github.com/Sirupsen/logrus"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	WebhookPayloadVersion        = 1
+	WebhookEventFuelAlert        = "fuel.alert"
+	WebhookEventFuelResolved     = "fuel.resolved"
+	WebhookEventStateChanged     = "starbase.state_changed"
+	WebhookEventStarbaseAdded    = "starbase.added"
+	WebhookEventStarbaseRemoved  = "starbase.removed"
+	WebhookHeaderEvent           = "X-POSbot-Event"
+	WebhookHeaderDelivery        = "X-POSbot-Delivery"
+	WebhookHeaderSignature       = "X-POSbot-Signature"
+	DefaultWebhookMaxAttempts    = 5
+	DefaultWebhookBackoff        = 5
+	WebhookDeadLetterRetention   = 100
+	WebhookDeadLetterListDefault = 10
+	WebhookWorkers               = 4
+	WebhookQueueSize             = 256
+	WebhookShutdownTimeout       = 30
+)
+
+type WebhookEvent struct {
+	Version   int         `json:"version"`
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	Timestamp time.Time   `json:"timestamp"`
+	Data      interface{} `json:"data"`
+}
+
+type WebhookStarbase struct {
+	ID              int    `json:"id"`
+	TypeID          int    `json:"typeID,omitempty"`
+	MoonID          int    `json:"moonID,omitempty"`
+	LocationName    string `json:"locationName,omitempty"`
+	SolarSystemID   int    `json:"solarSystemID,omitempty"`
+	SolarSystemName string `json:"solarSystemName,omitempty"`
+	RegionID        int    `json:"regionID,omitempty"`
+	RegionName      string `json:"regionName,omitempty"`
+	OwnerID         int    `json:"ownerID,omitempty"`
+	OwnerName       string `json:"ownerName,omitempty"`
+	State           string `json:"state"`
+}
+
+type WebhookFuel struct {
+	TypeID         int     `json:"typeID"`
+	TypeName       string  `json:"typeName"`
+	Quantity       int     `json:"quantity"`
+	HoursRemaining float64 `json:"hoursRemaining"`
+}
+
+type WebhookFuelAlert struct {
+	Starbase WebhookStarbase `json:"starbase"`
+	Fuel     WebhookFuel     `json:"fuel"`
+	Severity string          `json:"severity"`
+	Stage    string          `json:"stage"`
+}
+
+type WebhookFuelResolved struct {
+	Starbase WebhookStarbase `json:"starbase"`
+	Fuel     WebhookFuel     `json:"fuel"`
+}
+
+type WebhookStarbaseChange struct {
+	Starbase      WebhookStarbase `json:"starbase"`
+	PreviousState string          `json:"previousState,omitempty"`
+}
+
+type WebhookDeadLetter struct {
+	URL       string          `json:"url"`
+	EventID   string          `json:"eventID"`
+	EventType string          `json:"eventType"`
+	Payload   json.RawMessage `json:"payload"`
+	Attempts  int             `json:"attempts"`
+	Error     string          `json:"error"`
+	FailedAt  time.Time       `json:"failedAt"`
+}
+
+type WebhookNotifier struct {
+	URLs        []string
+	Secret      string
+	MaxAttempts int
+	Backoff     time.Duration
+	client      *http.Client
+	deadLetter  func(letter *WebhookDeadLetter) error
+	queue       chan *webhookDelivery
+	stopping    chan struct{}
+	closed      bool
+	mutex       sync.Mutex
+	workers     sync.WaitGroup
+}
+
+type webhookDelivery struct {
+	URL     string
+	Event   *WebhookEvent
+	Payload []byte
+}
+
+func NewWebhookNotifier(urls []string, secret string, maxAttempts int, backoff time.Duration, client *http.Client, deadLetter func(letter *WebhookDeadLetter) error) *WebhookNotifier {
+	n := &WebhookNotifier{
+		URLs:        urls,
+		Secret:      secret,
+		MaxAttempts: maxAttempts,
+		Backoff:     backoff,
+		client:      client,
+		deadLetter:  deadLetter,
+		queue:       make(chan *webhookDelivery, WebhookQueueSize),
+		stopping:    make(chan struct{}),
+	}
+
+	for i := 0; i < WebhookWorkers; i++ {
+		n.workers.Add(1)
+		go n.work()
+	}
+
+	return n
+}
+
+func newWebhookEvent(eventType string, data interface{}) *WebhookEvent {
+	id := make([]byte, 16)
+	rand.Read(id)
+
+	return &WebhookEvent{
+		Version:   WebhookPayloadVersion,
+		ID:        hex.EncodeToString(id),
+		Type:      eventType,
+		Timestamp: time.Now().UTC(),
+		Data:      data,
+	}
+}
+
+func newWebhookStarbaseFromPOS(pos *POS) WebhookStarbase {
+	return WebhookStarbase{
+		ID:              pos.ID,
+		LocationName:    pos.LocationName,
+		SolarSystemID:   pos.SolarSystemID,
+		SolarSystemName: pos.SolarSystemName,
+		RegionID:        pos.RegionID,
+		RegionName:      pos.RegionName,
+		OwnerID:         pos.OwnerID,
+		OwnerName:       pos.OwnerName,
+		State:           pos.State.String(),
+	}
+}
+
+func newWebhookStarbase(starbase *eveapi.Starbase) WebhookStarbase {
+	return WebhookStarbase{
+		ID:            starbase.ID,
+		TypeID:        starbase.TypeID,
+		MoonID:        starbase.MoonID,
+		SolarSystemID: starbase.LocationID,
+		OwnerID:       starbase.StandingOwnerID,
+		State:         starbase.State.String(),
+	}
+}
+
+func newWebhookFuel(fuel POSFuel) WebhookFuel {
+	return WebhookFuel{
+		TypeID:         fuel.TypeID,
+		TypeName:       fuel.TypeName,
+		Quantity:       fuel.Quantity,
+		HoursRemaining: fuel.HoursRemaining,
+	}
+}
+
+func (n *WebhookNotifier) Name() string {
+	return "webhook"
+}
+
+func (n *WebhookNotifier) Notify(alerts []*FuelAlert) error {
+	for _, alert := range alerts {
+		err := n.Publish(newWebhookEvent(WebhookEventFuelAlert, &WebhookFuelAlert{
+			Starbase: newWebhookStarbaseFromPOS(alert.POS),
+			Fuel:     newWebhookFuel(alert.Fuel),
+			Severity: alert.Severity,
+			Stage:    alert.Stage.Name,
+		}))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (n *WebhookNotifier) Publish(event *WebhookEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "Failed to marshal webhook event to JSON")
+	}
+
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	if n.closed {
+		return errors.New("Webhook notifier has been shut down")
+	}
+
+	for _, url := range n.URLs {
+		select {
+		case n.queue <- &webhookDelivery{URL: url, Event: event, Payload: payload}:
+		default:
+			n.storeDeadLetter(url, event, payload, 0, errors.New("Webhook delivery queue is full"))
+		}
+	}
+
+	return nil
+}
+
+func (n *WebhookNotifier) Shutdown() {
+	n.mutex.Lock()
+	if n.closed {
+		n.mutex.Unlock()
+		return
+	}
+	n.closed = true
+	close(n.stopping)
+	close(n.queue)
+	n.mutex.Unlock()
+
+	log.WithField("pending", len(n.queue)).Debug("Waiting for pending webhook deliveries")
+
+	done := make(chan struct{})
+	go func() {
+		n.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		log.Debug("Finished pending webhook deliveries")
+	case <-time.After(time.Second * WebhookShutdownTimeout):
+		log.WithField("pending", len(n.queue)).Warn("Timed out waiting for pending webhook deliveries")
+	}
+}
+
+func (n *WebhookNotifier) work() {
+	defer n.workers.Done()
+
+	for delivery := range n.queue {
+		n.deliver(delivery.URL, delivery.Event, delivery.Payload)
+	}
+}
+
+func (n *WebhookNotifier) Sign(payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(n.Secret))
+	mac.Write(payload)
+	return fmt.Sprintf("sha256=%s", hex.EncodeToString(mac.Sum(nil)))
+}
+
+func (n *WebhookNotifier) deliver(url string, event *WebhookEvent, payload []byte) {
+	var err error
+	attempts := 0
+	backoff := n.Backoff
+	for attempts < n.MaxAttempts {
+		attempts++
+
+		var retry bool
+		retry, err = n.post(url, event, payload)
+		if err == nil {
+			if alert, ok := event.Data.(*WebhookFuelAlert); ok {
+				recordNotificationSent(n.Name(), alert.Severity, 1)
+			}
+
+			log.WithFields(logrus.Fields{
+				"url":      url,
+				"eventID":  event.ID,
+				"type":     event.Type,
+				"attempts": attempts,
+			}).Debug("Delivered webhook event")
+			return
+		}
+
+		log.WithFields(logrus.Fields{
+			"url":      url,
+			"eventID":  event.ID,
+			"type":     event.Type,
+			"attempts": attempts,
+		}).WithError(err).Warn("Failed to deliver webhook event")
+
+		if !retry || attempts >= n.MaxAttempts {
+			break
+		}
+
+		// don't hold up shutdown with retries, the event is dead-lettered instead
+		stopping := false
+		select {
+		case <-time.After(backoff):
+		case <-n.stopping:
+			stopping = true
+		}
+		if stopping {
+			break
+		}
+		backoff *= 2
+	}
+
+	n.storeDeadLetter(url, event, payload, attempts, err)
+}
+
+func (n *WebhookNotifier) storeDeadLetter(url string, event *WebhookEvent, payload []byte, attempts int, err error) {
+	if event.Type == WebhookEventFuelAlert {
+		metricNotificationFailures.WithLabelValues(n.Name()).Inc()
+	}
+
+	letter := &WebhookDeadLetter{
+		URL:       url,
+		EventID:   event.ID,
+		EventType: event.Type,
+		Payload:   payload,
+		Attempts:  attempts,
+		Error:     err.Error(),
+		FailedAt:  time.Now().UTC(),
+	}
+	if n.deadLetter == nil {
+		return
+	}
+	if err = n.deadLetter(letter); err != nil {
+		log.WithFields(logrus.Fields{
+			"url":     url,
+			"eventID": event.ID,
+		}).WithError(err).Error("Failed to store dead-lettered webhook event")
+	}
+}
+
+func (n *WebhookNotifier) post(url string, event *WebhookEvent, payload []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return false, errors.Wrap(err, "Failed to create webhook request")
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", UserAgent)
+	req.Header.Set(WebhookHeaderEvent, event.Type)
+	req.Header.Set(WebhookHeaderDelivery, event.ID)
+	req.Header.Set(WebhookHeaderSignature, n.Sign(payload))
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return true, errors.Wrap(err, "Failed to send webhook request")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	body, _ := ioutil.ReadAll(resp.Body)
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusRequestTimeout
+	return retry, errors.Errorf("Webhook returned status %d: %s", resp.StatusCode, truncateString(string(body), 200))
+}
+
+func (b *Bot) publishWebhookEvent(eventType string, data interface{}) {
+	if b.webhook == nil {
+		return
+	}
+
+	if err := b.webhook.Publish(newWebhookEvent(eventType, data)); err != nil {
+		log.WithField("type", eventType).WithError(err).Warn("Failed to publish webhook event")
+	}
+}